@@ -1,39 +1,158 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"html/template"
+	"io/fs"
 	"log"
+	"log/slog"
+	"maps"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/firefly/packstring"
+	"github.com/firefly/packstring/internal/analytics"
+	"github.com/firefly/packstring/internal/assets"
+	"github.com/firefly/packstring/internal/attribution"
 	"github.com/firefly/packstring/internal/data"
 	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/gallery"
 	"github.com/firefly/packstring/internal/handlers"
+	"github.com/firefly/packstring/internal/jobs"
+	"github.com/firefly/packstring/internal/licenses"
+	"github.com/firefly/packstring/internal/mail"
+	"github.com/firefly/packstring/internal/metrics"
+	"github.com/firefly/packstring/internal/middleware"
+	"github.com/firefly/packstring/internal/posts"
+	"github.com/firefly/packstring/internal/pretrip"
+	"github.com/firefly/packstring/internal/resizer"
+	"github.com/firefly/packstring/internal/sitefs"
+	"github.com/firefly/packstring/internal/sitemap"
+	"github.com/firefly/packstring/internal/spam"
+	"github.com/firefly/packstring/internal/spool"
+	"github.com/firefly/packstring/internal/views"
 )
 
+// site holds the templates/ and static/ trees, siteFuncs the helpers every
+// template can use, and pageLoader parses pages from site. main sets them
+// up before parsing anything.
+var (
+	site       fs.FS
+	siteFuncs  = template.FuncMap{}
+	pageLoader *views.Loader
+)
+
+// siteFiles decides where templates and static files come from: the binary
+// in an embed build, otherwise the working directory. In dev mode the disk
+// copy is always used so edits show up. Files under PACKSTRING_OVERRIDE_DIR
+// replace their bundled counterparts; it is also where uploaded photos are
+// written, since an embedded tree can't take new files. It returns the
+// layered tree and the directory for uploads.
+func siteFiles(devMode bool) (fs.FS, string) {
+	overrideDir := os.Getenv("PACKSTRING_OVERRIDE_DIR")
+	embedded := packstring.Embedded != nil && !devMode
+	if overrideDir == "" && embedded {
+		// Uploads need somewhere that survives a redeploy; data/ is the
+		// persistent volume in production.
+		overrideDir = filepath.Join("data", "site")
+	}
+
+	var layers sitefs.Overlay
+	if overrideDir != "" {
+		layers = append(layers, os.DirFS(overrideDir))
+		log.Printf("Site overrides read from %s", overrideDir)
+	}
+	if embedded {
+		layers = append(layers, packstring.Embedded)
+		log.Println("Serving templates and static files embedded in the binary")
+	} else {
+		layers = append(layers, os.DirFS("."))
+	}
+	if overrideDir == "" {
+		return layers, "."
+	}
+	return layers, overrideDir
+}
+
 // mustParseTemplate builds a template set for a single page file,
 // combining it with the base layout and all partials.
-func mustParseTemplate(page string) *template.Template {
-	tmpl := template.Must(template.ParseGlob(filepath.Join("templates", "layouts", "*.html")))
-	template.Must(tmpl.ParseGlob(filepath.Join("templates", "partials", "*.html")))
-	template.Must(tmpl.ParseFiles(filepath.Join("templates", "pages", page)))
-	return tmpl
+func mustParseTemplate(page string) *views.Page {
+	return pageLoader.MustPage(page, siteFuncs)
 }
 
 // mustParseAdminTemplate builds a template set with the admin FuncMap.
-func mustParseAdminTemplate(page string) *template.Template {
-	tmpl := template.Must(
-		template.New("").Funcs(handlers.AdminFuncMap()).ParseGlob(filepath.Join("templates", "layouts", "*.html")),
-	)
-	template.Must(tmpl.ParseGlob(filepath.Join("templates", "partials", "*.html")))
-	template.Must(tmpl.ParseFiles(filepath.Join("templates", "pages", page)))
-	return tmpl
+func mustParseAdminTemplate(page string) *views.Page {
+	return pageLoader.MustPage(page, siteFuncs, handlers.AdminFuncMap())
+}
+
+// mustSub returns the subtree of fsys at dir.
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", dir, err)
+	}
+	return sub
+}
+
+// captchaVerifier returns the CAPTCHA verifier named by CAPTCHA_VERIFIER, or
+// nil for none.
+func captchaVerifier(name string) spam.Verifier {
+	switch name {
+	case "":
+		return nil
+	case "fake":
+		log.Println("CAPTCHA checks use the local fake verifier")
+		return spam.Fake{}
+	}
+	log.Fatalf("Unknown CAPTCHA_VERIFIER %q", name)
+	return nil
+}
+
+// mustAddJob registers a scheduled job, exiting on a bad schedule.
+func mustAddJob(sched *jobs.Scheduler, name, spec string, fn jobs.Func) {
+	if err := sched.Add(name, spec, fn); err != nil {
+		log.Fatalf("Failed to register job: %v", err)
+	}
 }
 
 func main() {
+	devMode := os.Getenv("PACKSTRING_DEV") == "1"
+	slog.SetDefault(newLogger(os.Getenv("LOG_FORMAT")))
+	var uploadRoot string
+	site, uploadRoot = siteFiles(devMode)
+
+	// Fingerprinted static files, linked from templates via {{asset}}
+	static, err := assets.New(mustSub(site, "static"), devMode)
+	if err != nil {
+		log.Fatalf("Failed to load static files: %v", err)
+	}
+	maps.Copy(siteFuncs, static.FuncMap())
+	maps.Copy(siteFuncs, resizer.FuncMap())
+
+	dbPath := os.Getenv("DATABASE_PATH")
+	if dbPath == "" {
+		dbPath = "data/packstring.db"
+	}
+
+	// Contact form spam checks; the form token key lives beside the database
+	// so tokens outlast a restart
+	tokenKey, err := spam.LoadKey(filepath.Join(filepath.Dir(dbPath), "form-token.key"))
+	if err != nil {
+		log.Fatalf("Failed to load form token key: %v", err)
+	}
+	scorer := spam.New(tokenKey, captchaVerifier(os.Getenv("CAPTCHA_VERIFIER")))
+	maps.Copy(siteFuncs, scorer.FuncMap())
+
+	// Pages are parsed now; in dev mode they re-parse when their files change
+	pageLoader = views.NewLoader(site, devMode)
+
 	// Build a separate template set per page to avoid "content" block collisions
-	templates := map[string]*template.Template{
+	templates := views.Pages{
 		"home":     mustParseTemplate("home.html"),
 		"trips":    mustParseTemplate("trips.html"),
 		"fishing":  mustParseTemplate("fishing.html"),
@@ -41,61 +160,136 @@ func main() {
 		"packages": mustParseTemplate("packages.html"),
 		"gallery":  mustParseTemplate("gallery.html"),
 		"contact":  mustParseTemplate("contact.html"),
+		"gear":     mustParseTemplate("gear.html"),
+		"reports":  mustParseTemplate("reports.html"),
+		"report":   mustParseTemplate("report.html"),
+		"error":    mustParseTemplate("error.html"),
 	}
 
-	devMode := os.Getenv("PACKSTRING_DEV") == "1"
 	availability := data.NewAvailabilityStore("data/availability.yaml", devMode)
+	reports := posts.NewStore(site, "reports", devMode)
 
 	// Initialize SQLite database
-	dbPath := os.Getenv("DATABASE_PATH")
-	if dbPath == "" {
-		dbPath = "data/packstring.db"
-	}
 	store, err := db.Open(dbPath)
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
-	defer store.Close()
 	log.Printf("Database opened at %s", dbPath)
 
-	pages := handlers.NewPages(templates, availability)
+	pages := handlers.NewPages(templates, availability, store, reports)
+
+	// Built-in, cookieless page view counts, rolled up in SQLite
+	tracker := analytics.New(store)
+
+	// Scheduled background jobs (times are server-local)
+	mailer := mail.FromEnv()
+	sched := jobs.New(store)
+	mustAddJob(sched, "license-reminders", "15 * * * *", func(ctx context.Context) error {
+		return licenses.SendReminders(store, mailer, time.Now())
+	})
+	pretripDays := pretrip.DaysFromEnv()
+	mustAddJob(sched, "pretrip-emails", "0 8 * * *", func(ctx context.Context) error {
+		return pretrip.SendEmails(store, mailer, pretripDays, time.Now())
+	})
+	// Inquiries the database refuses wait here; the job retries them
+	inquirySpool := spool.New(filepath.Join(filepath.Dir(dbPath), "inquiry-spool.jsonl"))
+	mustAddJob(sched, "replay-inquiry-spool", "*/5 * * * *", func(ctx context.Context) error {
+		n, remaining, err := inquirySpool.Replay(store)
+		if n > 0 {
+			log.Printf("[spool] replayed %d inquiries", n)
+		}
+		if err == nil && remaining > 0 {
+			err = fmt.Errorf("%d unreadable line(s) left in %s", remaining, inquirySpool.Path())
+		}
+		return err
+	})
+	mustAddJob(sched, "prune-job-history", "30 3 * * *", func(ctx context.Context) error {
+		n, err := store.PruneJobRuns(time.Now().AddDate(0, 0, -90))
+		if n > 0 {
+			log.Printf("[jobs] pruned %d old run(s)", n)
+		}
+		return err
+	})
 
 	mux := http.NewServeMux()
 
+	// Monitoring
+	mux.HandleFunc("GET /healthz", handlers.Healthz)
+	mux.HandleFunc("GET /readyz", handlers.Readyz(store, pageLoader))
+	mux.HandleFunc("GET /metrics", handlers.Metrics(metrics.Default, os.Getenv("METRICS_TOKEN")))
+
 	// Static files
-	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+	mux.Handle("GET "+assets.Prefix+"{path...}", static)
 
-	// SEO files
-	mux.HandleFunc("GET /sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "static/sitemap.xml")
-	})
-	mux.HandleFunc("GET /robots.txt", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "static/robots.txt")
-	})
+	// Resized images, generated from the originals and cached next to the
+	// database unless IMAGE_CACHE_DIR says otherwise
+	imageCache := os.Getenv("IMAGE_CACHE_DIR")
+	if imageCache == "" {
+		imageCache = filepath.Join(filepath.Dir(dbPath), "cache", "img")
+	}
+	mux.Handle("GET "+resizer.Prefix+"{preset}/{path...}", resizer.New(mustSub(site, "static/img"), imageCache))
 
-	// Pages
-	mux.HandleFunc("GET /{$}", pages.HomePage)
-	mux.HandleFunc("GET /trips/{$}", pages.TripsHub)
-	mux.HandleFunc("GET /trips/fishing/{$}", pages.FishingPage)
-	mux.HandleFunc("GET /trips/hunting/{$}", pages.HuntingPage)
-	mux.HandleFunc("GET /trips/packages/{$}", pages.PackagesPage)
-	mux.HandleFunc("GET /gallery/{$}", pages.GalleryPage)
-	mux.HandleFunc("GET /contact/{$}", pages.ContactPage)
+	// Public pages. Each is registered together with its sitemap entry so the
+	// sitemap can't drift from the routes.
+	data.SetSiteURL(os.Getenv("SITE_URL"))
+	siteURL := data.BaseURL()
+	sm := sitemap.New(siteURL)
+	page := func(path string, h http.HandlerFunc, priority float64, changeFreq string, lastMod func() time.Time) {
+		mux.HandleFunc("GET "+path+"{$}", h)
+		sm.Page(path, priority, changeFreq, lastMod)
+	}
+	pageModTime := func(file string) func() time.Time {
+		return sitemap.FileModTime(site, "templates/pages/"+file)
+	}
+	page("/", pages.HomePage, 1.0, "weekly", sitemap.Newest(pageModTime("home.html"), pages.ReportsModTime))
+	page("/trips/", pages.TripsHub, 0.9, "monthly", pageModTime("trips.html"))
+	page("/trips/fishing/", pages.FishingPage, 0.9, "weekly", sitemap.Newest(pageModTime("fishing.html"), availability.ModTime, pages.ReportsModTime))
+	page("/trips/hunting/", pages.HuntingPage, 0.9, "weekly", sitemap.Newest(pageModTime("hunting.html"), availability.ModTime))
+	page("/trips/packages/", pages.PackagesPage, 0.8, "weekly", sitemap.Newest(pageModTime("packages.html"), availability.ModTime, pages.ReportsModTime))
+	page("/reports/", pages.ReportsPage, 0.7, "weekly", sitemap.Newest(pageModTime("reports.html"), pages.ReportsModTime))
+	page("/gallery/", pages.GalleryPage, 0.6, "monthly", sitemap.Newest(pageModTime("gallery.html"), pages.GalleryModTime))
+	page("/contact/", pages.ContactPage, 0.7, "yearly", pageModTime("contact.html"))
+
+	mux.HandleFunc("GET /trips/{slug}/gear/{$}", pages.GearPage)
+	sm.Add("gear", pages.GearURLs(pageModTime("gear.html")))
+	mux.HandleFunc("GET /reports/{slug}/{$}", pages.ReportPage)
+	sm.Add("reports", pages.ReportURLs)
+	mux.HandleFunc("GET /reports/feed.xml", pages.ReportsRSS)
+	mux.HandleFunc("GET /reports/atom.xml", pages.ReportsAtom)
+
+	// SEO files
+	mux.Handle("GET /sitemap.xml", sm)
+	mux.HandleFunc("GET /sitemaps/{part}", sm.ServePart)
+	mux.HandleFunc("GET /robots.txt", handlers.Robots(site, siteURL))
+	log.Printf("Sitemap URLs use %s", siteURL)
 
 	// Contact form
-	contact := handlers.NewContact(templates, store)
+	contact := handlers.NewContact(templates, store, inquirySpool, scorer, mailer, os.Getenv("OUTFITTER_EMAIL"))
 	mux.HandleFunc("POST /contact", contact.Submit)
 
 	// Admin routes (only if ADMIN_PASSWORD is set)
 	adminPassword := os.Getenv("ADMIN_PASSWORD")
 	if adminPassword != "" {
-		adminTemplates := map[string]*template.Template{
-			"admin-login":          mustParseTemplate("admin-login.html"),
-			"admin":                mustParseAdminTemplate("admin.html"),
-			"admin-dashboard":      mustParseAdminTemplate("admin-dashboard.html"),
-			"admin-inquiries":      mustParseAdminTemplate("admin-inquiries.html"),
-			"admin-inquiry-detail": mustParseAdminTemplate("admin-inquiry-detail.html"),
-			"admin-deposits":       mustParseAdminTemplate("admin-deposits.html"),
+		adminTemplates := views.Pages{
+			"admin-login":           mustParseTemplate("admin-login.html"),
+			"admin":                 mustParseAdminTemplate("admin.html"),
+			"admin-dashboard":       mustParseAdminTemplate("admin-dashboard.html"),
+			"admin-inquiries":       mustParseAdminTemplate("admin-inquiries.html"),
+			"admin-inquiry-detail":  mustParseAdminTemplate("admin-inquiry-detail.html"),
+			"admin-deposits":        mustParseAdminTemplate("admin-deposits.html"),
+			"admin-schedule":        mustParseAdminTemplate("admin-schedule.html"),
+			"admin-resources":       mustParseAdminTemplate("admin-resources.html"),
+			"admin-waivers":         mustParseAdminTemplate("admin-waivers.html"),
+			"admin-waiver":          mustParseAdminTemplate("admin-waiver.html"),
+			"admin-manifest":        mustParseAdminTemplate("admin-manifest.html"),
+			"admin-licenses":        mustParseAdminTemplate("admin-licenses.html"),
+			"admin-sources":         mustParseAdminTemplate("admin-sources.html"),
+			"admin-analytics":       mustParseAdminTemplate("admin-analytics.html"),
+			"admin-insights":        mustParseAdminTemplate("admin-insights.html"),
+			"admin-jobs":            mustParseAdminTemplate("admin-jobs.html"),
+			"admin-fishing-reports": mustParseAdminTemplate("admin-fishing-reports.html"),
+			"admin-fishing-report":  mustParseAdminTemplate("admin-fishing-report.html"),
+			"admin-gallery":         mustParseAdminTemplate("admin-gallery.html"),
 		}
 		admin := handlers.NewAdmin(adminTemplates, availability, adminPassword, store)
 
@@ -105,7 +299,7 @@ func main() {
 		mux.HandleFunc("POST /admin/logout", admin.Logout)
 
 		// Dashboard
-		mux.HandleFunc("GET /admin/{$}", admin.RequireAuth(admin.Dashboard))
+		mux.HandleFunc("GET /admin/{$}", admin.RequireAuth(admin.Dashboard(inquirySpool)))
 
 		// Availability
 		mux.HandleFunc("GET /admin/availability/{$}", admin.RequireAuth(admin.EditPage))
@@ -116,35 +310,195 @@ func main() {
 		mux.HandleFunc("GET /admin/inquiries/{id}", admin.RequireAuth(admin.InquiryDetail))
 		mux.HandleFunc("POST /admin/inquiries/{id}/status", admin.RequireAuth(admin.UpdateInquiryStatus))
 		mux.HandleFunc("POST /admin/inquiries/{id}/notes", admin.RequireAuth(admin.UpdateInquiryNotes))
+		mux.HandleFunc("POST /admin/inquiries/{id}/schedule", admin.RequireAuth(admin.UpdateInquirySchedule))
+		mux.HandleFunc("POST /admin/inquiries/{id}/waivers", admin.RequireAuth(admin.CreateWaivers))
+		mux.HandleFunc("POST /admin/inquiries/{id}/manifest", admin.RequireAuth(admin.CreateManifestLink))
+		mux.HandleFunc("POST /admin/inquiries/{id}/licenses", admin.RequireAuth(admin.SaveInquiryLicenses))
+		mux.HandleFunc("GET /admin/licenses/{$}", admin.RequireAuth(admin.LicensesReport))
+		mux.HandleFunc("GET /admin/sources/{$}", admin.RequireAuth(admin.SourcesReport))
+		mux.HandleFunc("GET /admin/analytics/{$}", admin.RequireAuth(admin.AnalyticsPage))
+		mux.HandleFunc("GET /admin/insights/{$}", admin.RequireAuth(admin.InsightsPage))
+
+		// Schedule and resources
+		mux.HandleFunc("GET /admin/schedule/{$}", admin.RequireAuth(admin.SchedulePage))
+		mux.HandleFunc("GET /admin/resources/{$}", admin.RequireAuth(admin.ResourcesPage))
+		mux.HandleFunc("POST /admin/resources", admin.RequireAuth(admin.SaveResource))
+		mux.HandleFunc("POST /admin/resources/{id}", admin.RequireAuth(admin.SaveResource))
+		mux.HandleFunc("GET /admin/manifest/{$}", admin.RequireAuth(admin.DayManifestPage))
 
 		// Deposits
 		mux.HandleFunc("GET /admin/deposits/{$}", admin.RequireAuth(admin.DepositsPage))
 		mux.HandleFunc("POST /admin/deposits", admin.RequireAuth(admin.SaveDeposits))
 		mux.HandleFunc("POST /admin/inquiries/{id}/deposit", admin.RequireAuth(admin.GenerateDepositLink))
 
+		// Waivers
+		mux.HandleFunc("GET /admin/waivers/{$}", admin.RequireAuth(admin.WaiverTemplatesPage))
+		mux.HandleFunc("POST /admin/waivers", admin.RequireAuth(admin.PublishWaiverTemplate))
+		mux.HandleFunc("GET /admin/waivers/{id}", admin.RequireAuth(admin.WaiverView))
+		mux.HandleFunc("GET /admin/waivers/{id}/pdf", admin.RequireAuth(admin.WaiverPDF))
+
+		// Fishing reports
+		mux.HandleFunc("GET /admin/fishing-reports/{$}", admin.RequireAuth(admin.FishingReportsPage))
+		mux.HandleFunc("GET /admin/fishing-reports/new", admin.RequireAuth(admin.FishingReportEditor))
+		mux.HandleFunc("GET /admin/fishing-reports/{id}", admin.RequireAuth(admin.FishingReportEditor))
+		mux.HandleFunc("POST /admin/fishing-reports", admin.RequireAuth(admin.SaveFishingReport))
+		mux.HandleFunc("POST /admin/fishing-reports/preview", admin.RequireAuth(admin.PreviewFishingReport))
+		mux.HandleFunc("POST /admin/fishing-reports/{id}", admin.RequireAuth(admin.SaveFishingReport))
+		mux.HandleFunc("POST /admin/fishing-reports/{id}/delete", admin.RequireAuth(admin.DeleteFishingReport))
+
+		// Gallery
+		photos := gallery.NewLibrary(uploadRoot)
+		mux.HandleFunc("GET /admin/gallery/{$}", admin.RequireAuth(admin.GalleryPage))
+		mux.HandleFunc("POST /admin/gallery", admin.RequireAuth(admin.UploadPhoto(photos)))
+		mux.HandleFunc("POST /admin/gallery/{id}", admin.RequireAuth(admin.UpdatePhoto))
+		mux.HandleFunc("POST /admin/gallery/{id}/move", admin.RequireAuth(admin.MovePhoto))
+		mux.HandleFunc("POST /admin/gallery/{id}/delete", admin.RequireAuth(admin.DeletePhoto(photos)))
+
+		// Background jobs
+		mux.HandleFunc("GET /admin/jobs/{$}", admin.RequireAuth(admin.JobsPage(sched)))
+		mux.HandleFunc("POST /admin/jobs/{name}/run", admin.RequireAuth(admin.RunJob(sched)))
+		mustAddJob(sched, "session-cleanup", "*/15 * * * *", func(ctx context.Context) error {
+			if n := admin.EvictExpiredSessions(); n > 0 {
+				log.Printf("[admin] evicted %d expired session(s)", n)
+			}
+			return nil
+		})
+
 		// Stripe webhook (no auth — verified by signature)
 		stripe := handlers.NewStripeHandler(store)
 		mux.HandleFunc("POST /stripe/webhook", stripe.HandleWebhook)
 
 		// Public payment pages
-		paymentTemplates := map[string]*template.Template{
+		paymentTemplates := views.Pages{
 			"payment-success": mustParseTemplate("payment-success.html"),
 			"payment-cancel":  mustParseTemplate("payment-cancel.html"),
 		}
 		mux.HandleFunc("GET /payments/success", handlers.PaymentSuccess(paymentTemplates))
 		mux.HandleFunc("GET /payments/cancel", handlers.PaymentCancel(paymentTemplates))
 
+		// Public waiver signing (token-gated links sent to each guest)
+		waivers := handlers.NewWaivers(views.Pages{
+			"waiver": mustParseTemplate("waiver.html"),
+		}, store)
+		mux.HandleFunc("GET /waivers/{token}", waivers.SignPage)
+		mux.HandleFunc("POST /waivers/{token}", waivers.Sign)
+
+		// Public guest manifest (lead client fills in the party)
+		manifests := handlers.NewManifests(views.Pages{
+			"manifest": mustParseTemplate("manifest.html"),
+		}, store)
+		mux.HandleFunc("GET /manifest/{token}", manifests.Page)
+		mux.HandleFunc("POST /manifest/{token}", manifests.Submit)
+
 		log.Println("Admin routes registered at /admin/")
 	} else {
 		log.Println("ADMIN_PASSWORD not set — admin routes disabled")
 	}
 
+	// SIGTERM (a deploy) or SIGINT (Ctrl-C) starts a graceful shutdown; a
+	// second signal kills the process immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	sched.Start(ctx)
+	go tracker.Run(ctx)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
-	log.Printf("Starting server on :%s", port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
+	trustedProxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	// Every request gets an ID first so the access log and any panic report
+	// carry it, and a client address that only trusted proxies can set;
+	// recovery sits inside the log so a panic is logged as a 500.
+	// Plain-text errors from handlers and the mux become branded pages.
+	// Page views are counted for the built-in analytics, and first page
+	// views get the source cookie inquiries are attributed by.
+	errorPages := handlers.NewErrors(templates)
+	handler := middleware.Chain(mux,
+		middleware.RequestID,
+		middleware.ClientIP(trustedProxies),
+		middleware.AccessLog(slog.Default()),
+		middleware.Metrics,
+		middleware.Observe(tracker.Record),
+		middleware.Recover(slog.Default(), errorPages.ServerError),
+		middleware.Errors(errorPages.Render),
+		middleware.SecurityHeaders,
+		attribution.Capture,
+	)
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second, // uploads extend their own deadline
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Starting server on :%s", port)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
 		log.Fatal(err)
+	case <-ctx.Done():
+	}
+	stop()
+	shutdown(srv, sched, contact, tracker, store)
+}
+
+// newLogger returns the logger for access logs and errors: logfmt-style text
+// by default, or one JSON object per line with LOG_FORMAT=json for log
+// shippers. Once it's the default, the log package's output goes through it
+// too.
+func newLogger(format string) *slog.Logger {
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+}
+
+// drainTimeout bounds how long shutdown waits for in-flight requests and job
+// runs. It must be shorter than the container's stop grace period.
+const drainTimeout = 20 * time.Second
+
+// shutdown stops accepting connections and lets in-flight requests finish
+// (a Stripe webhook mid-flight still gets recorded), then waits for running
+// jobs, so a batch of reminder emails isn't cut off halfway, and finally
+// writes the last page view counts and closes the database.
+func shutdown(srv *http.Server, sched *jobs.Scheduler, contact *handlers.Contact, tracker *analytics.Tracker, store *db.Store) {
+	log.Printf("Shutting down; draining for up to %s", drainTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server did not drain cleanly: %v", err)
+	}
+
+	// The scheduler stopped starting runs when the signal arrived; the
+	// email jobs don't watch their context, so runs already sending finish.
+	// Spool alerts sent by requests the server just drained finish too.
+	done := make(chan struct{})
+	go func() {
+		sched.Wait()
+		contact.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println("[jobs] runs or spool alerts still in progress at the drain deadline; exiting anyway")
+	}
+
+	if err := tracker.Flush(); err != nil {
+		log.Printf("[analytics] final flush: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		log.Printf("Error closing database: %v", err)
 	}
+	log.Println("Shutdown complete")
 }
@@ -0,0 +1,14 @@
+//go:build embed
+
+package packstring
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed templates static reports
+var files embed.FS
+
+// Embedded holds the templates/, static/ and reports/ trees.
+var Embedded fs.FS = files
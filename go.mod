@@ -4,6 +4,7 @@ go 1.25.1
 
 require (
 	github.com/stripe/stripe-go/v81 v81.4.0
+	golang.org/x/image v0.25.0
 	gopkg.in/yaml.v3 v3.0.1
 	modernc.org/sqlite v1.46.1
 )
@@ -0,0 +1,231 @@
+// Package analytics counts page views on the server, with no cookies and
+// no third-party script. Each view of a public page adds to daily rollups
+// by path, referring host, device class and trip. Unique visitors are
+// counted with a hash of the visitor's IP address and user agent, salted
+// with a random value that lives only in memory and is replaced every day,
+// so a visitor can't be followed from one day to the next and nothing that
+// identifies them is stored.
+package analytics
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firefly/packstring/internal/attribution"
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/middleware"
+)
+
+// flushInterval is how often counts are written to the database.
+const flushInterval = time.Minute
+
+// private lists route prefixes that are never counted: the admin, and
+// token links sent to one client, whose paths are secrets.
+var private = []string{"/admin", "/waivers/", "/manifest/", "/payments/"}
+
+// tripsListed maps each trip category page to the trips it lists.
+var tripsListed = func() map[string][]string {
+	m := map[string][]string{}
+	for _, path := range []string{"/trips/fishing/", "/trips/hunting/", "/trips/packages/"} {
+		m[path] = data.TripsListed(path)
+	}
+	return m
+}()
+
+// bots are user agent fragments of crawlers, link previews and scripts.
+var bots = []string{
+	"bot", "crawl", "spider", "slurp", "preview", "facebookexternalhit",
+	"curl", "wget", "python", "go-http-client", "headless", "lighthouse",
+}
+
+// Tracker counts page views. It is safe for concurrent use.
+type Tracker struct {
+	store *db.Store
+	now   func() time.Time
+
+	mu      sync.Mutex
+	day     string
+	salt    []byte
+	seen    map[string]bool // visitor hash, kind and key already counted today
+	pending map[pendingKey]*db.AnalyticsCount
+}
+
+type pendingKey struct {
+	day, kind, key string
+}
+
+// New returns a tracker writing to store.
+func New(store *db.Store) *Tracker {
+	return &Tracker{store: store, now: time.Now, pending: map[pendingKey]*db.AnalyticsCount{}}
+}
+
+// Record counts r if it was a visitor loading a public page. It is meant
+// for middleware.Observe.
+func (t *Tracker) Record(r *http.Request, status int) {
+	if status != http.StatusOK || !pageView(r) {
+		return
+	}
+
+	keys := [][2]string{
+		{"site", ""},
+		{"page", r.URL.Path},
+		{"device", Device(r.UserAgent())},
+	}
+	if host := attribution.ReferrerHost(r); host != "" {
+		keys = append(keys, [2]string{"referrer", host})
+	}
+	// Trips are read about on their category page, so a view of the page
+	// is a view of every trip on it.
+	for _, slug := range tripsListed[r.URL.Path] {
+		keys = append(keys, [2]string{"trip_view", slug})
+	}
+	if r.URL.Path == "/contact/" {
+		if slug := r.URL.Query().Get("trip"); slug != "" {
+			if _, ok := data.FindTrip(slug); ok {
+				keys = append(keys, [2]string{"trip_contact", slug})
+			}
+		}
+	}
+
+	now := t.now().UTC()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover(now)
+	visitor := t.visitor(r)
+	for _, k := range keys {
+		pk := pendingKey{t.day, k[0], k[1]}
+		c := t.pending[pk]
+		if c == nil {
+			c = &db.AnalyticsCount{Day: pk.day, Kind: pk.kind, Key: pk.key}
+			t.pending[pk] = c
+		}
+		c.Views++
+		if id := visitor + "|" + k[0] + "|" + k[1]; !t.seen[id] {
+			t.seen[id] = true
+			c.Visitors++
+		}
+	}
+}
+
+// pageView reports whether r is a person's browser loading a public page:
+// a matched GET route asking for HTML, not an htmx swap, a prefetch or a
+// known bot.
+func pageView(r *http.Request) bool {
+	if r.Method != http.MethodGet || r.Pattern == "" || r.Header.Get("HX-Request") != "" {
+		return false
+	}
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		return false
+	}
+	if p := r.Header.Get("Sec-Purpose") + r.Header.Get("Purpose"); strings.Contains(p, "prefetch") {
+		return false
+	}
+	for _, p := range private {
+		if strings.HasPrefix(r.URL.Path, p) {
+			return false
+		}
+	}
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		return false
+	}
+	for _, b := range bots {
+		if strings.Contains(ua, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// rollover starts a new day with a fresh salt, forgetting who was seen.
+// The caller holds t.mu.
+func (t *Tracker) rollover(now time.Time) {
+	day := now.Format(db.DateLayout)
+	if day == t.day {
+		return
+	}
+	t.day = day
+	t.salt = make([]byte, 32)
+	rand.Read(t.salt)
+	t.seen = map[string]bool{}
+}
+
+// visitor returns today's anonymous ID for the visitor making r. The
+// caller holds t.mu.
+func (t *Tracker) visitor(r *http.Request) string {
+	h := sha256.New()
+	h.Write(t.salt)
+	h.Write([]byte(middleware.GetClientIP(r) + "|" + r.UserAgent()))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// Device returns the coarse device class for a user agent: mobile, tablet
+// or desktop.
+func Device(ua string) string {
+	switch {
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet"):
+		return "tablet"
+	case strings.Contains(ua, "Mobi") || strings.Contains(ua, "iPhone"):
+		return "mobile"
+	case strings.Contains(ua, "Android"):
+		return "tablet" // Android without "Mobile" is a tablet
+	}
+	return "desktop"
+}
+
+// Flush writes the counts gathered since the last flush. If the database
+// refuses them they are kept for the next try.
+func (t *Tracker) Flush() error {
+	t.mu.Lock()
+	if len(t.pending) == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	pending := t.pending
+	t.pending = map[pendingKey]*db.AnalyticsCount{}
+	t.mu.Unlock()
+
+	counts := make([]db.AnalyticsCount, 0, len(pending))
+	for _, c := range pending {
+		counts = append(counts, *c)
+	}
+	if err := t.store.AddAnalytics(counts); err != nil {
+		t.mu.Lock()
+		for k, c := range pending {
+			if cur := t.pending[k]; cur != nil {
+				cur.Views += c.Views
+				cur.Visitors += c.Visitors
+			} else {
+				t.pending[k] = c
+			}
+		}
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes counts every minute until ctx is done. The final flush is
+// left to the caller, once the server has stopped taking requests.
+func (t *Tracker) Run(ctx context.Context) {
+	tick := time.NewTicker(flushInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if err := t.Flush(); err != nil {
+				log.Printf("[analytics] flush: %v", err)
+			}
+		}
+	}
+}
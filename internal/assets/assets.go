@@ -0,0 +1,253 @@
+// Package assets serves /static/ with content-hashed URLs. At startup every
+// file under the static directory is hashed into a manifest; templates link
+// to "/static/css/output.3f9a1c0b2d.css" through the asset func, and those
+// URLs are cached by browsers forever. Precompressed .br and .gz siblings
+// are sent to clients that accept them. Files are read through an fs.FS so
+// they can come from the binary or from disk.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefix is where the static directory is mounted.
+const Prefix = "/static/"
+
+const (
+	// immutable is for fingerprinted URLs: the content can never change.
+	immutable = "public, max-age=31536000, immutable"
+	// unversioned is for everything else, e.g. fonts referenced from CSS.
+	unversioned = "public, max-age=86400"
+)
+
+// hashLen is how many hex digits of the SHA-256 go into a URL.
+const hashLen = 10
+
+// fingerprint matches the hash inserted before a file's extension.
+var fingerprint = regexp.MustCompile(`\.[0-9a-f]{10}(\.[^./]+)$`)
+
+// encodings are the precompressed variants looked for, best first.
+var encodings = []struct{ name, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+type entry struct {
+	hash string
+	size int64
+	mod  time.Time
+}
+
+// Manifest maps static files to their fingerprinted names.
+type Manifest struct {
+	fsys fs.FS
+	dev  bool
+
+	mu    sync.RWMutex
+	files map[string]entry // "css/output.css" → hash
+}
+
+// New hashes every file in fsys, the static directory. In dev mode a file
+// is re-hashed when it changes, so a Tailwind rebuild shows up on the next
+// page load.
+func New(fsys fs.FS, dev bool) (*Manifest, error) {
+	m := &Manifest{fsys: fsys, dev: dev, files: make(map[string]entry)}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !servable(d.Name()) {
+			return nil
+		}
+		e, err := m.hashFile(p)
+		if err != nil {
+			return err
+		}
+		m.files[p] = e
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build asset manifest: %w", err)
+	}
+	log.Printf("[assets] %d files fingerprinted", len(m.files))
+	return m, nil
+}
+
+// servable reports whether a file name is part of the site rather than a
+// dotfile or a precompressed copy.
+func servable(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, enc := range encodings {
+		if strings.HasSuffix(name, enc.ext) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Manifest) hashFile(p string) (entry, error) {
+	f, err := m.fsys.Open(p)
+	if err != nil {
+		return entry{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return entry{}, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return entry{}, fmt.Errorf("hash %s: %w", p, err)
+	}
+	return entry{hash: hex.EncodeToString(h.Sum(nil))[:hashLen], size: info.Size(), mod: info.ModTime()}, nil
+}
+
+// lookup returns the entry for a static file, hashing it on the spot if it
+// appeared or (in dev mode) changed since startup.
+func (m *Manifest) lookup(name string) (entry, bool) {
+	m.mu.RLock()
+	e, ok := m.files[name]
+	m.mu.RUnlock()
+	if ok && !m.dev {
+		return e, true
+	}
+	if !fs.ValidPath(name) {
+		return entry{}, false
+	}
+	info, err := fs.Stat(m.fsys, name)
+	if err != nil || !info.Mode().IsRegular() || !servable(path.Base(name)) {
+		return entry{}, false
+	}
+	if ok && info.Size() == e.size && info.ModTime().Equal(e.mod) {
+		return e, true
+	}
+	e, err = m.hashFile(name)
+	if err != nil {
+		log.Printf("[assets] %v", err)
+		return entry{}, false
+	}
+	m.mu.Lock()
+	m.files[name] = e
+	m.mu.Unlock()
+	return e, true
+}
+
+// URL returns the fingerprinted URL of a file in the static directory, e.g.
+// "css/output.css". A file that doesn't exist keeps its plain URL, so a
+// missing build step shows up as a 404 rather than a template error.
+func (m *Manifest) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	e, ok := m.lookup(name)
+	if !ok {
+		return Prefix + name
+	}
+	ext := path.Ext(name)
+	return Prefix + strings.TrimSuffix(name, ext) + "." + e.hash + ext
+}
+
+// FuncMap provides {{asset "css/output.css"}} to templates.
+func (m *Manifest) FuncMap() template.FuncMap {
+	return template.FuncMap{"asset": m.URL}
+}
+
+// ServeHTTP serves GET /static/{path...}. Fingerprinted URLs are cached
+// for a year; a stale fingerprint from an old page still gets the current
+// file, just without the long cache. Directories are never listed.
+func (m *Manifest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("path")
+	if name == "" || path.Clean("/"+name) != "/"+name {
+		http.NotFound(w, r)
+		return
+	}
+
+	cache := unversioned
+	var hash string
+	if sub := fingerprint.FindStringSubmatchIndex(name); sub != nil {
+		hash = name[sub[0]+1 : sub[0]+1+hashLen]
+		name = name[:sub[0]] + name[sub[2]:sub[3]]
+	}
+	e, ok := m.lookup(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if hash != "" {
+		if hash == e.hash {
+			cache = immutable
+		} else {
+			cache = "no-cache"
+		}
+	}
+
+	file := name
+	h := w.Header()
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+	h.Set("Content-Type", ctype)
+	h.Set("Cache-Control", cache)
+	h.Set("X-Content-Type-Options", "nosniff")
+	etag := e.hash
+
+	for _, enc := range encodings {
+		info, err := fs.Stat(m.fsys, file+enc.ext)
+		if err != nil || info.ModTime().Before(e.mod) {
+			continue
+		}
+		h.Set("Vary", "Accept-Encoding")
+		if accepts(r, enc.name) {
+			file += enc.ext
+			h.Set("Content-Encoding", enc.name)
+			etag += "-" + enc.name
+			break
+		}
+	}
+	h.Set("ETag", `"`+etag+`"`)
+
+	f, err := m.fsys.Open(file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	http.ServeContent(w, r, "", info.ModTime(), rs)
+}
+
+// accepts reports whether the request's Accept-Encoding allows coding.
+func accepts(r *http.Request, coding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), coding) {
+			continue
+		}
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
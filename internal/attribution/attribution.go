@@ -0,0 +1,215 @@
+// Package attribution remembers where a visitor came from. On the first
+// page they load, the landing page, the external referrer and any UTM
+// parameters go into a first-party cookie; when they send an inquiry the
+// cookie is read back and turned into a source, medium and campaign.
+package attribution
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/db"
+)
+
+// CookieName is the first-party cookie holding the first touch.
+const CookieName = "pk_src"
+
+// maxAge is how long a first touch is remembered. Trips are often planned
+// months ahead, so it's generous.
+const maxAge = 180 * 24 * time.Hour
+
+// maxField caps each value kept in the cookie, so a long referrer or
+// landing URL can't push the cookie past what browsers accept.
+const maxField = 200
+
+// utmParams are the campaign parameters kept from the landing URL.
+var utmParams = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+
+// skip lists paths that aren't pages a visitor lands on.
+var skip = []string{"/admin", "/static/", "/img/", "/healthz", "/readyz", "/metrics", "/stripe/"}
+
+// Capture sets the source cookie on a visitor's first page view. Later
+// visits keep the original, so the report credits whatever first brought
+// them to the site.
+func Capture(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if landing(r) {
+			if _, err := r.Cookie(CookieName); err == http.ErrNoCookie {
+				http.SetCookie(w, &http.Cookie{
+					Name:     CookieName,
+					Value:    touch(r, time.Now()).Encode(),
+					Path:     "/",
+					MaxAge:   int(maxAge.Seconds()),
+					HttpOnly: true,
+					Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// landing reports whether r is a browser loading a page, as opposed to an
+// asset, an htmx swap or a form post.
+func landing(r *http.Request) bool {
+	if r.Method != http.MethodGet || r.Header.Get("HX-Request") != "" {
+		return false
+	}
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		return false
+	}
+	for _, p := range skip {
+		if strings.HasPrefix(r.URL.Path, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// touch records the first visit: the page, the referrer if it's another
+// site, and the UTM parameters.
+func touch(r *http.Request, now time.Time) url.Values {
+	v := url.Values{}
+	v.Set("landing", clip(r.URL.RequestURI()))
+	if ref, err := url.Parse(r.Referer()); err == nil && ref.Host != "" && !sameSite(ref.Host, r.Host) {
+		v.Set("referrer", clip(ref.String()))
+	}
+	q := r.URL.Query()
+	for _, p := range utmParams {
+		if s := strings.TrimSpace(q.Get(p)); s != "" {
+			v.Set(p, clip(s))
+		}
+	}
+	v.Set("at", strconv.FormatInt(now.Unix(), 10))
+	return v
+}
+
+// ReferrerHost returns the host of the page that linked to r, without
+// "www.", or "" if there is none or it's this site.
+func ReferrerHost(r *http.Request) string {
+	ref, err := url.Parse(r.Referer())
+	if err != nil || ref.Host == "" || sameSite(ref.Host, r.Host) {
+		return ""
+	}
+	return strings.TrimPrefix(hostname(ref.Host), "www.")
+}
+
+func clip(s string) string {
+	if len(s) > maxField {
+		return s[:maxField]
+	}
+	return s
+}
+
+func sameSite(a, b string) bool {
+	return strings.TrimPrefix(hostname(a), "www.") == strings.TrimPrefix(hostname(b), "www.")
+}
+
+// hostname strips any port and lowercases.
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// FromRequest reads the source cookie and works out where the visitor came
+// from. Without a cookie (blocked, or a visitor from before the cookie
+// existed) the result is empty, which the report shows as unknown.
+func FromRequest(r *http.Request) db.Attribution {
+	c, err := r.Cookie(CookieName)
+	if err != nil {
+		return db.Attribution{}
+	}
+	v, err := url.ParseQuery(c.Value)
+	if err != nil {
+		return db.Attribution{}
+	}
+	return FromValues(v)
+}
+
+// FromValues derives the attribution from a recorded first touch. UTM
+// parameters win; then ad click IDs on the landing URL; then the referrer;
+// and with none of those the visit was direct.
+func FromValues(v url.Values) db.Attribution {
+	a := db.Attribution{
+		Campaign:    v.Get("utm_campaign"),
+		Term:        v.Get("utm_term"),
+		Content:     v.Get("utm_content"),
+		Referrer:    v.Get("referrer"),
+		LandingPage: v.Get("landing"),
+	}
+	if src := v.Get("utm_source"); src != "" {
+		a.Source = strings.ToLower(src)
+		a.Medium = strings.ToLower(v.Get("utm_medium"))
+		return a
+	}
+
+	var landingQuery url.Values
+	if u, err := url.Parse(a.LandingPage); err == nil {
+		landingQuery = u.Query()
+	}
+	switch {
+	case landingQuery.Get("gclid") != "":
+		a.Source, a.Medium = "google", "cpc"
+		return a
+	case landingQuery.Get("fbclid") != "":
+		a.Source, a.Medium = "facebook", "social"
+		return a
+	}
+
+	if a.Referrer != "" {
+		a.Source, a.Medium = classify(a.Referrer)
+		return a
+	}
+	a.Source, a.Medium = "(direct)", "(none)"
+	return a
+}
+
+// knownReferrers maps a referring domain's name to the source and medium it
+// is reported as. Domains are matched by their second-level label, so
+// google.com and google.ca both count as google.
+var knownReferrers = map[string][2]string{
+	"google":     {"google", "organic"},
+	"bing":       {"bing", "organic"},
+	"duckduckgo": {"duckduckgo", "organic"},
+	"yahoo":      {"yahoo", "organic"},
+	"ecosia":     {"ecosia", "organic"},
+	"facebook":   {"facebook", "social"},
+	"fb":         {"facebook", "social"},
+	"instagram":  {"instagram", "social"},
+	"t":          {"twitter", "social"}, // t.co
+	"twitter":    {"twitter", "social"},
+	"x":          {"twitter", "social"},
+	"youtube":    {"youtube", "social"},
+	"pinterest":  {"pinterest", "social"},
+	"reddit":     {"reddit", "social"},
+	"linkedin":   {"linkedin", "social"},
+}
+
+// classify returns the source and medium for a referrer URL: a known search
+// engine or social network, otherwise the referring site as a referral.
+func classify(referrer string) (source, medium string) {
+	u, err := url.Parse(referrer)
+	if err != nil || u.Host == "" {
+		return "(direct)", "(none)"
+	}
+	host := strings.TrimPrefix(hostname(u.Host), "www.")
+	labels := strings.Split(host, ".")
+	for i := len(labels) - 2; i >= 0; i-- {
+		if known, ok := knownReferrers[labels[i]]; ok {
+			return known[0], known[1]
+		}
+		// Only look past the registered name for country domains like
+		// google.co.uk, where the second-level label is generic.
+		if len(labels[i]) > 3 {
+			break
+		}
+	}
+	return host, "referral"
+}
@@ -0,0 +1,138 @@
+// Package charts draws bar charts as inline SVG on the server, so the admin
+// reports need no JavaScript charting library. Charts scale to the width of
+// their container and carry a <title> on each bar for hover values.
+package charts
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Colors from the site palette, for series fills.
+const (
+	Copper = "#B8652A"
+	Forest = "#2E6B45"
+	River  = "#3A6B6E"
+	Stone  = "#8A7E6E"
+)
+
+const (
+	gridColor = "#DED6C8" // sand-dk
+	textColor = "#6B5F50" // ink-faded
+)
+
+// Chart dimensions in SVG user units. The rendered size follows the
+// container; these only fix the proportions.
+const (
+	width     = 640
+	height    = 220
+	padLeft   = 52
+	padRight  = 8
+	padTop    = 28 // room for the legend
+	padBottom = 22 // room for the category labels
+	gridLines = 4
+)
+
+// Series is one set of bars, a value per label.
+type Series struct {
+	Name   string
+	Color  string
+	Values []float64
+}
+
+// Bars is a grouped bar chart: for each label, one bar per series side by
+// side.
+type Bars struct {
+	Title  string // read out by screen readers
+	Labels []string
+	Series []Series
+	// Format renders axis and hover values. Nil shows whole numbers.
+	Format func(float64) string
+}
+
+// SVG renders the chart.
+func (c Bars) SVG() template.HTML {
+	format := c.Format
+	if format == nil {
+		format = func(v float64) string { return strconv.FormatFloat(v, 'f', 0, 64) }
+	}
+
+	peak := 0.0
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			peak = math.Max(peak, v)
+		}
+	}
+	step := niceStep(peak / gridLines)
+	top := step * gridLines
+
+	plotW := float64(width - padLeft - padRight)
+	plotH := float64(height - padTop - padBottom)
+	y := func(v float64) float64 { return padTop + plotH - v/top*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="w-full h-auto" role="img" aria-label="%s" font-size="11" fill="%s">`,
+		width, height, template.HTMLEscapeString(c.Title), textColor)
+
+	// Grid lines and the value axis.
+	for i := 0; i <= gridLines; i++ {
+		v := step * float64(i)
+		fmt.Fprintf(&b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" stroke="%s" stroke-width="1"/>`,
+			padLeft, width-padRight, y(v), y(v), gridColor)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`,
+			padLeft-6, y(v), template.HTMLEscapeString(format(v)))
+	}
+
+	// Bars, grouped by label.
+	if n := len(c.Labels); n > 0 && len(c.Series) > 0 {
+		group := plotW / float64(n)
+		bar := group * 0.8 / float64(len(c.Series))
+		for i, label := range c.Labels {
+			x0 := padLeft + group*float64(i) + group*0.1
+			for j, s := range c.Series {
+				if i >= len(s.Values) {
+					continue
+				}
+				v := s.Values[i]
+				h := math.Max(0, v/top*plotH)
+				fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" rx="1"><title>%s %s: %s</title></rect>`,
+					x0+bar*float64(j), y(0)-h, math.Max(bar-1, 1), h, s.Color,
+					template.HTMLEscapeString(label), template.HTMLEscapeString(s.Name), template.HTMLEscapeString(format(v)))
+			}
+			fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`,
+				padLeft+group*(float64(i)+0.5), height-6, template.HTMLEscapeString(label))
+		}
+	}
+
+	// Legend, right-aligned above the plot.
+	x := float64(width - padRight)
+	for i := len(c.Series) - 1; i >= 0; i-- {
+		s := c.Series[i]
+		x -= float64(len(s.Name))*6.5 + 4
+		fmt.Fprintf(&b, `<text x="%.1f" y="12" dominant-baseline="middle">%s</text>`, x, template.HTMLEscapeString(s.Name))
+		x -= 14
+		fmt.Fprintf(&b, `<rect x="%.1f" y="6" width="10" height="10" fill="%s" rx="1"/>`, x, s.Color)
+		x -= 12
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// niceStep rounds a raw grid step up to 1, 2 or 5 times a power of ten,
+// and never below 1, so the axis reads in round whole numbers.
+func niceStep(raw float64) float64 {
+	if raw <= 1 {
+		return 1
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*mag {
+			return m * mag
+		}
+	}
+	return 10 * mag
+}
@@ -5,10 +5,15 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/firefly/packstring/internal/metrics"
 )
 
 // DateSlot represents a single date range with availability status.
@@ -61,12 +66,14 @@ func (s *AvailabilityStore) load() {
 	raw, err := os.ReadFile(s.path)
 	if err != nil {
 		log.Printf("[availability] warning: cannot read %s: %v", s.path, err)
+		metrics.AvailabilityReloads.Inc("error")
 		return
 	}
 
 	var af AvailabilityFile
 	if err := yaml.Unmarshal(raw, &af); err != nil {
 		log.Printf("[availability] warning: cannot parse %s: %v", s.path, err)
+		metrics.AvailabilityReloads.Inc("error")
 		return
 	}
 
@@ -86,6 +93,7 @@ func (s *AvailabilityStore) load() {
 	info, err := os.Stat(s.path)
 	if err != nil {
 		log.Printf("[availability] warning: cannot stat %s: %v", s.path, err)
+		metrics.AvailabilityReloads.Inc("error")
 		return
 	}
 
@@ -94,6 +102,7 @@ func (s *AvailabilityStore) load() {
 	s.modTime = info.ModTime()
 	s.mu.Unlock()
 
+	metrics.AvailabilityReloads.Inc("ok")
 	log.Printf("[availability] loaded %d trips from %s", len(af.Trips), s.path)
 }
 
@@ -114,6 +123,16 @@ func (s *AvailabilityStore) GetAll() map[string][]DateSlot {
 	return out
 }
 
+// ModTime returns when availability.yaml was last changed.
+func (s *AvailabilityStore) ModTime() time.Time {
+	if s.devMode {
+		s.reloadIfChanged()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.modTime
+}
+
 // Save validates the given trips, writes them atomically to the YAML file, and updates the in-memory cache.
 func (s *AvailabilityStore) Save(trips map[string][]DateSlot) error {
 	// Validate statuses
@@ -190,3 +209,66 @@ func (s *AvailabilityStore) reloadIfChanged() {
 		s.load()
 	}
 }
+
+// slotMonths maps the month spellings used in availability.yaml to months.
+var slotMonths = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "sept": time.September, "oct": time.October,
+	"nov": time.November, "dec": time.December,
+}
+
+var slotDayPattern = regexp.MustCompile(`^([A-Za-z]+)\.?\s+(\d{1,2})$`)
+
+// ParseSlotDates interprets a slot label such as "Jun 16 – Jun 30" or
+// "Apr 15 – May 31 (Spring)" as a concrete date range. Labels carry no year,
+// so the range is placed at its next occurrence on or after now. Month-only
+// labels like "Dec – Feb" are too coarse to schedule against and return false.
+func ParseSlotDates(label string, now time.Time) (start, end time.Time, ok bool) {
+	if i := strings.Index(label, "("); i >= 0 {
+		label = label[:i]
+	}
+	parts := strings.FieldsFunc(label, func(r rune) bool { return r == '–' || r == '—' || r == '-' })
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	sm, sd, ok1 := parseSlotDay(parts[0])
+	em, ed, ok2 := parseSlotDay(parts[1])
+	if !ok1 || !ok2 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	year := now.Year()
+	start = time.Date(year, sm, sd, 0, 0, 0, 0, time.UTC)
+	end = time.Date(year, em, ed, 0, 0, 0, 0, time.UTC)
+	if end.Before(start) {
+		end = end.AddDate(1, 0, 0)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if end.Before(today) {
+		start = start.AddDate(1, 0, 0)
+		end = end.AddDate(1, 0, 0)
+	}
+	return start, end, true
+}
+
+func parseSlotDay(s string) (time.Month, int, bool) {
+	m := slotDayPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, 0, false
+	}
+	key := strings.ToLower(m[1])
+	month, ok := slotMonths[key]
+	if !ok && len(key) > 3 {
+		month, ok = slotMonths[key[:3]]
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	day, err := strconv.Atoi(m[2])
+	if err != nil || day < 1 || day > 31 {
+		return 0, 0, false
+	}
+	return month, day, true
+}
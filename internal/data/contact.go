@@ -1,8 +1,18 @@
 package data
 
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/validate"
+)
+
 // ContactPageData holds data rendered on the /contact/ page.
 type ContactPageData struct {
 	Meta PageMeta
+	Form ContactForm // empty on first load
 }
 
 // GetContactPageData returns metadata for the contact page.
@@ -11,8 +21,9 @@ func GetContactPageData() ContactPageData {
 		Meta: PageMeta{
 			Title:        "Book a Trip — MT Hunt & Fish Outfitters",
 			Description:  "Contact Forrest Fawthrop to book a guided fishing or hunting trip out of Helena, Montana. Call (406) 459-5352 or send an inquiry.",
-			CanonicalURL: SiteURL + "/contact/",
-			OGImage:      SiteURL + "/static/img/hero/hero-montana-1600w.webp",
+			CanonicalURL: BaseURL() + "/contact/",
+			OGImage:      BaseURL() + "/static/img/hero/hero-montana-1600w.webp",
+			Breadcrumbs:  Breadcrumbs("Contact", "/contact/"),
 		},
 	}
 }
@@ -46,3 +57,118 @@ func TripDisplayName(slug string) string {
 	}
 	return slug
 }
+
+// Length caps for the contact form's free-text fields, in characters.
+const (
+	maxNameLen    = 100
+	maxEmailLen   = 254 // the longest address SMTP allows
+	maxPhoneLen   = 32
+	maxDatesLen   = 100
+	maxMessageLen = 5000
+)
+
+// Experience levels offered on the contact form.
+var experienceLevels = []string{"beginner", "intermediate", "experienced"}
+
+// ContactForm is a contact form submission: the values as typed, then
+// normalized by Validate, plus an error per field that failed. It is
+// rendered back into the form when there are errors.
+type ContactForm struct {
+	Name       string
+	Email      string
+	Phone      string // E.164 once valid, e.g. "+14064595352"
+	Trip       string // trip slug
+	Dates      string
+	PartySize  string // a number once valid
+	Experience string
+	Message    string
+	Token      string // spam check form token, kept when the form is re-rendered
+
+	Errors    validate.Errors // field name → message
+	FormError string          // a problem with the submission as a whole
+}
+
+// ParseContactForm reads a submission from form values and validates it.
+func ParseContactForm(v url.Values) ContactForm {
+	f := ContactForm{
+		Name:       strings.TrimSpace(v.Get("name")),
+		Email:      strings.TrimSpace(v.Get("email")),
+		Phone:      strings.TrimSpace(v.Get("phone")),
+		Trip:       strings.TrimSpace(v.Get("trip")),
+		Dates:      strings.TrimSpace(v.Get("dates")),
+		PartySize:  strings.TrimSpace(v.Get("party_size")),
+		Experience: strings.TrimSpace(v.Get("experience")),
+		Message:    strings.TrimSpace(v.Get("message")),
+		Token:      v.Get("form_token"),
+	}
+	f.Validate()
+	return f
+}
+
+// Validate checks every field, normalizing the ones that pass, and records
+// a message for each one that doesn't. Error keys match the form's input
+// names.
+func (f *ContactForm) Validate() {
+	e := validate.Errors{}
+
+	switch {
+	case f.Name == "":
+		e.Add("name", "We need your name so Forrest knows who he's talking to.")
+	case !validate.MaxLen(f.Name, maxNameLen):
+		e.Add("name", "That name is a bit long. Keep it under 100 characters.")
+	}
+
+	switch {
+	case f.Email == "":
+		e.Add("email", "We need your email to get back to you.")
+	case !validate.MaxLen(f.Email, maxEmailLen):
+		e.Add("email", "That email address is too long.")
+	default:
+		if addr, ok := validate.Email(f.Email); ok {
+			f.Email = addr
+		} else {
+			e.Add("email", "That doesn't look like an email address. Check for typos, like name@example.com.")
+		}
+	}
+
+	if f.Phone != "" {
+		if n, ok := validate.Phone(f.Phone); ok && validate.MaxLen(f.Phone, maxPhoneLen) {
+			f.Phone = n
+		} else {
+			e.Add("phone", "That phone number doesn't look right. Use a 10-digit US number, or start with + and the country code.")
+		}
+	}
+
+	if f.Trip != "" {
+		if _, ok := FindTrip(f.Trip); !ok {
+			e.Add("trip", "Pick a trip from the list, or leave it blank if you're still deciding.")
+		}
+	}
+
+	if !validate.MaxLen(f.Dates, maxDatesLen) {
+		e.Add("dates", "Keep the dates short. Put any details in the message.")
+	}
+
+	if f.PartySize != "" {
+		if n, ok := validate.Int(f.PartySize, 1, db.MaxPartySize); ok {
+			f.PartySize = strconv.Itoa(n)
+		} else {
+			e.Add("party_size", "Party size should be a number from 1 to 20. For bigger groups, say so in the message.")
+		}
+	}
+
+	if f.Experience != "" && !validate.OneOf(f.Experience, experienceLevels...) {
+		e.Add("experience", "Pick an experience level from the list.")
+	}
+
+	if !validate.MaxLen(f.Message, maxMessageLen) {
+		e.Add("message", "That message is over 5,000 characters. Trim it down and Forrest can get the rest on the phone.")
+	}
+
+	f.Errors = e
+}
+
+// Valid reports whether the submission passed validation.
+func (f *ContactForm) Valid() bool {
+	return !f.Errors.Any() && f.FormError == ""
+}
@@ -0,0 +1,72 @@
+package data
+
+// Shared "what to bring" lists. Trips add their own items on top with gearList.
+var (
+	fishingGear = []string{
+		"Valid Montana fishing license (buy online from Montana FWP)",
+		"Polarized sunglasses and a brimmed hat",
+		"Rain jacket, even if the forecast is clear",
+		"Layers you can shed: the river is cold in the morning and hot by noon",
+		"Sunscreen and lip balm",
+		"Camera or phone in a waterproof bag",
+	}
+	huntingGear = []string{
+		"Hunting license, tags, and hunter education card",
+		"Rifle or bow you've sighted in, with at least 40 rounds or your arrows",
+		"Hunter orange vest and hat (required for rifle season)",
+		"Broken-in boots with ankle support",
+		"Wool or synthetic layers, no cotton",
+		"Daypack with water, headlamp, and knife",
+	}
+	packageGear = []string{
+		"Valid Montana fishing license",
+		"Hunting license, tags, and hunter education card for the hunting days",
+		"Rifle or bow you've sighted in (everything else is provided)",
+		"Hunter orange vest and hat",
+		"Broken-in boots and a pair of camp shoes",
+		"Rain jacket and warm layers for mornings on the water",
+		"Polarized sunglasses, hat, and sunscreen",
+	}
+)
+
+// gearList returns the shared list followed by any trip-specific items.
+func gearList(base []string, extra ...string) []string {
+	out := make([]string, 0, len(base)+len(extra))
+	out = append(out, base...)
+	return append(out, extra...)
+}
+
+// TripsListed returns the slugs of the trips a category page lists, or nil
+// for any other path.
+func TripsListed(path string) []string {
+	var trips []TripSection
+	switch path {
+	case "/trips/fishing/":
+		trips = GetFishingPageData().Trips
+	case "/trips/hunting/":
+		trips = GetHuntingPageData().Trips
+	case "/trips/packages/":
+		trips = GetPackagesPageData().Packages
+	default:
+		return nil
+	}
+	slugs := make([]string, len(trips))
+	for i, t := range trips {
+		slugs[i] = t.Slug
+	}
+	return slugs
+}
+
+// FindTrip looks up a trip anywhere in the catalog by slug.
+func FindTrip(slug string) (TripSection, bool) {
+	var all []TripSection
+	all = append(all, GetFishingPageData().Trips...)
+	all = append(all, GetHuntingPageData().Trips...)
+	all = append(all, GetPackagesPageData().Packages...)
+	for _, t := range all {
+		if t.Slug == slug {
+			return t, true
+		}
+	}
+	return TripSection{}, false
+}
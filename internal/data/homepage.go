@@ -1,5 +1,10 @@
 package data
 
+import (
+	"github.com/firefly/packstring/internal/posts"
+	"github.com/firefly/packstring/internal/schema"
+)
+
 // TripCard represents a summary card for a trip category.
 type TripCard struct {
 	Title       string
@@ -20,16 +25,18 @@ type HomePageData struct {
 	Meta         PageMeta
 	TripCards    []TripCard
 	Testimonials []Testimonial
+	Reports      []posts.Post // latest published fishing reports
 }
 
-// GetHomePageData returns seed content for the homepage.
-func GetHomePageData() HomePageData {
-	return HomePageData{
+// GetHomePageData returns seed content for the homepage along with the
+// latest fishing reports.
+func GetHomePageData(reports []posts.Post) HomePageData {
+	d := HomePageData{
 		Meta: PageMeta{
 			Title:        "MT Hunt & Fish Outfitters — Helena, Montana Fishing & Hunting Guide",
 			Description:  "Guided fishing and hunting trips out of Helena, Montana. Missouri River trout, elk, deer, bear, and antelope. 25 years of experience. Call Forrest at (406) 459-5352.",
-			CanonicalURL: SiteURL + "/",
-			OGImage:      SiteURL + "/static/img/hero/hero-montana-1600w.webp",
+			CanonicalURL: BaseURL() + "/",
+			OGImage:      BaseURL() + "/static/img/hero/hero-montana-1600w.webp",
 		},
 		TripCards: []TripCard{
 			{
@@ -68,5 +75,8 @@ func GetHomePageData() HomePageData {
 				Detail: "Triple Header Package, August 2025",
 			},
 		},
+		Reports: reports,
 	}
+	d.Meta.Schema = []schema.Node{BusinessSchema(d.Testimonials)}
+	return d
 }
@@ -1,5 +1,50 @@
 package data
 
+import "time"
+
+// LicenseRequirement is a Montana license, permit, or tag a hunter needs
+// before a hunt. Drawing licenses must be applied for by an annual deadline.
+type LicenseRequirement struct {
+	Type          string     // e.g. "Nonresident Big Game Combination"
+	Drawing       bool       // true if issued through a limited-entry drawing
+	DeadlineMonth time.Month // application or purchase deadline; zero if none
+	DeadlineDay   int
+	Notes         string
+}
+
+// Deadline returns the deadline as "April 1", or "" if the license has none.
+func (l LicenseRequirement) Deadline() string {
+	if l.DeadlineMonth == 0 {
+		return ""
+	}
+	return time.Date(2000, l.DeadlineMonth, l.DeadlineDay, 0, 0, 0, 0, time.UTC).Format("January 2")
+}
+
+// DeadlineBefore returns the last deadline on or before the given date, i.e.
+// the one that applies to a trip starting then. ok is false if the license
+// has no deadline.
+func (l LicenseRequirement) DeadlineBefore(t time.Time) (deadline time.Time, ok bool) {
+	if l.DeadlineMonth == 0 {
+		return time.Time{}, false
+	}
+	d := time.Date(t.Year(), l.DeadlineMonth, l.DeadlineDay, 0, 0, 0, 0, t.Location())
+	if d.After(t) {
+		d = d.AddDate(-1, 0, 0)
+	}
+	return d, true
+}
+
+// TripLicenses returns the licenses required for a hunting trip slug, or nil
+// for trips that need none.
+func TripLicenses(slug string) []LicenseRequirement {
+	for _, t := range GetHuntingPageData().Trips {
+		if t.Slug == slug {
+			return t.Licenses
+		}
+	}
+	return nil
+}
+
 // HuntingPageData holds all data rendered on the /trips/hunting/ page.
 type HuntingPageData struct {
 	Meta  PageMeta
@@ -12,8 +57,9 @@ func GetHuntingPageData() HuntingPageData {
 		Meta: PageMeta{
 			Title:        "Hunting Trips — MT Hunt & Fish Outfitters",
 			Description:  "Guided elk, deer, bear, and antelope hunts in the Elkhorn and Big Belt mountains near Helena, Montana. Private ranch and public land access.",
-			CanonicalURL: SiteURL + "/trips/hunting/",
-			OGImage:      SiteURL + "/static/img/trips/hunting-card-800w.webp",
+			CanonicalURL: BaseURL() + "/trips/hunting/",
+			OGImage:      BaseURL() + "/static/img/trips/hunting-card-800w.webp",
+			Breadcrumbs:  Breadcrumbs("Trips", "/trips/", "Hunting", "/trips/hunting/"),
 		},
 		Trips: []TripSection{
 			{
@@ -35,8 +81,18 @@ func GetHuntingPageData() HuntingPageData {
 					"Camp setup and breakdown",
 					"Spotting scopes and optics",
 				},
+				Licenses: []LicenseRequirement{
+					{Type: "Conservation and Base Hunting License", Notes: "Required of every hunter. Sold over the counter year-round."},
+					{Type: "Nonresident Big Game Combination", Drawing: true, DeadlineMonth: time.April, DeadlineDay: 1, Notes: "Includes the general elk and deer tags. Residents buy a general elk license over the counter."},
+				},
 				Duration: "5–7 Days",
 				Price:    "Contact for pricing",
+				Gear:     gearList(huntingGear, "Trekking poles for steep country", "Binoculars if you have a pair you like"),
+				Prep: []string{
+					"Get in hiking shape before you come. Elk country is steep and the days are long.",
+					"Sight in your rifle at the range distances you expect to shoot, up to 300 yards.",
+					"Send license and tag numbers as soon as you have them. We cannot hunt without them.",
+				},
 			},
 			{
 				Title:         "Deer Hunts",
@@ -57,8 +113,17 @@ func GetHuntingPageData() HuntingPageData {
 					"Stand or blind setup where applicable",
 					"Transport to and from hunting areas",
 				},
+				Licenses: []LicenseRequirement{
+					{Type: "Conservation and Base Hunting License", Notes: "Required of every hunter. Sold over the counter year-round."},
+					{Type: "Nonresident Deer Combination", Drawing: true, DeadlineMonth: time.April, DeadlineDay: 1, Notes: "Residents buy a general deer license over the counter."},
+				},
 				Duration: "3–5 Days",
 				Price:    "Contact for pricing",
+				Gear:     gearList(huntingGear, "Warm seat pad for long sits in a stand or blind"),
+				Prep: []string{
+					"Sight in your rifle before the trip. Most shots are 100 to 250 yards.",
+					"Send license and tag numbers as soon as you have them. We cannot hunt without them.",
+				},
 			},
 			{
 				Title:         "Bear Hunts",
@@ -79,8 +144,19 @@ func GetHuntingPageData() HuntingPageData {
 					"Pack-out assistance",
 					"Spotting scopes and optics",
 				},
+				Licenses: []LicenseRequirement{
+					{Type: "Conservation and Base Hunting License", Notes: "Required of every hunter. Sold over the counter year-round."},
+					{Type: "Black Bear License", Notes: "Sold over the counter. Must be bought before the season opens and carried while hunting."},
+					{Type: "Bear Identification Test", Notes: "Certificate required before buying a bear license."},
+				},
 				Duration: "5–7 Days",
 				Price:    "Contact for pricing",
+				Gear:     gearList(huntingGear, "Bug spray and a head net for spring bait sits"),
+				Prep: []string{
+					"Pass the Montana bear identification test before you buy your license.",
+					"Spring hunts mean long, quiet evenings on bait. Bring something warm to sit in.",
+					"Send license and tag numbers as soon as you have them. We cannot hunt without them.",
+				},
 			},
 			{
 				Title:         "Antelope Hunts",
@@ -101,8 +177,18 @@ func GetHuntingPageData() HuntingPageData {
 					"Transport to and from hunting areas",
 					"Game care and cooling",
 				},
+				Licenses: []LicenseRequirement{
+					{Type: "Conservation and Base Hunting License", Notes: "Required of every hunter. Sold over the counter year-round."},
+					{Type: "Antelope License", Drawing: true, DeadlineMonth: time.June, DeadlineDay: 1, Notes: "All antelope licenses are issued by drawing."},
+				},
 				Duration: "2–3 Days",
 				Price:    "Contact for pricing",
+				Gear:     gearList(huntingGear, "Bipod or shooting sticks for long prairie shots"),
+				Prep: []string{
+					"Practice shooting at 300 to 400 yards. Antelope rarely let you get closer.",
+					"It gets hot on the prairie. Bring more water than you think you need.",
+					"Send license and tag numbers as soon as you have them. We cannot hunt without them.",
+				},
 			},
 		},
 	}
@@ -1,12 +1,86 @@
 package data
 
-// SiteURL is the canonical base URL for the demo site.
+import (
+	"html/template"
+	"log"
+	"strings"
+
+	"github.com/firefly/packstring/internal/resizer"
+	"github.com/firefly/packstring/internal/schema"
+)
+
+// SiteURL is the canonical base URL for the demo site, used until
+// SetSiteURL configures the deployment's own.
 const SiteURL = "https://mthuntfish.com"
 
+var baseURL = SiteURL
+
+// SetSiteURL sets the base URL that canonical links, structured data and
+// emails are built on, normally from SITE_URL so they agree with the
+// sitemap. Call it once at startup, before serving. An empty value keeps
+// SiteURL.
+func SetSiteURL(u string) {
+	if u = strings.TrimRight(u, "/"); u != "" {
+		baseURL = u
+	}
+}
+
+// BaseURL returns the site's absolute base URL without a trailing slash.
+func BaseURL() string {
+	return baseURL
+}
+
+// ImageURL returns the absolute URL of image base at a resizer preset, for
+// og:image and structured data. Going through the resizer means the URL
+// works whatever format the variant was written in.
+func ImageURL(base, preset string) string {
+	u, err := resizer.URL(base, preset)
+	if err != nil {
+		log.Printf("[meta] image %s: %v", base, err)
+	}
+	return BaseURL() + u
+}
+
 // PageMeta holds SEO metadata rendered in the <head> of every page.
 type PageMeta struct {
 	Title        string // feeds <title> and og:title
 	Description  string // feeds <meta description> and og:description
 	CanonicalURL string // absolute URL
 	OGImage      string // absolute URL to OG image
+
+	Breadcrumbs []schema.Crumb // trail from the homepage; empty on the homepage itself
+	Schema      []schema.Node  // page-specific structured data, e.g. TouristTrip nodes
+}
+
+// JSONLD renders the page's structured data: the business, anything in
+// Schema, and the breadcrumb trail. A page may supply its own LocalBusiness
+// node (the homepage adds reviews) in place of the default one.
+func (m PageMeta) JSONLD() template.JS {
+	var nodes []schema.Node
+	hasBusiness := false
+	for _, n := range m.Schema {
+		hasBusiness = hasBusiness || n.Type() == "LocalBusiness"
+	}
+	if !hasBusiness {
+		nodes = append(nodes, BusinessSchema(nil))
+	}
+	nodes = append(nodes, m.Schema...)
+	if len(m.Breadcrumbs) > 0 {
+		nodes = append(nodes, schema.BreadcrumbList(m.Breadcrumbs))
+	}
+	js, err := schema.Graph(nodes...)
+	if err != nil {
+		log.Printf("[schema] %s: %v", m.CanonicalURL, err)
+	}
+	return js
+}
+
+// Breadcrumbs builds a trail starting at the homepage from name, path pairs,
+// e.g. Breadcrumbs("Trips", "/trips/", "Fishing", "/trips/fishing/").
+func Breadcrumbs(namePaths ...string) []schema.Crumb {
+	crumbs := []schema.Crumb{{Name: "Home", URL: BaseURL() + "/"}}
+	for i := 0; i+1 < len(namePaths); i += 2 {
+		crumbs = append(crumbs, schema.Crumb{Name: namePaths[i], URL: BaseURL() + namePaths[i+1]})
+	}
+	return crumbs
 }
@@ -12,8 +12,9 @@ func GetPackagesPageData() PackagesPageData {
 		Meta: PageMeta{
 			Title:        "Multi-Day Packages — MT Hunt & Fish Outfitters",
 			Description:  "Multi-day fishing and hunting packages in Montana. The Triple Header (5 days) and the 6-Pack (7 days). Lodging, meals, gear, and guide service included.",
-			CanonicalURL: SiteURL + "/trips/packages/",
-			OGImage:      SiteURL + "/static/img/trips/packages-card-800w.webp",
+			CanonicalURL: BaseURL() + "/trips/packages/",
+			OGImage:      BaseURL() + "/static/img/trips/packages-card-800w.webp",
+			Breadcrumbs:  Breadcrumbs("Trips", "/trips/", "Packages", "/trips/packages/"),
 		},
 		Packages: []TripSection{
 			{
@@ -38,6 +39,12 @@ func GetPackagesPageData() PackagesPageData {
 				},
 				Duration: "5 Days / 4 Nights",
 				Price:    "$3,500/person",
+				Gear:     gearList(packageGear),
+				Prep: []string{
+					"Forrest picks you up at Helena Regional Airport. Send your flight details when you book travel.",
+					"Lodging is arranged for you. Pack for four nights with laundry midweek if you need it.",
+					"If you fly with a firearm, declare it at check-in and bring a locking hard case.",
+				},
 			},
 			{
 				Title:   "Montana 6-Pack",
@@ -63,6 +70,12 @@ func GetPackagesPageData() PackagesPageData {
 				},
 				Duration: "7 Days / 6 Nights",
 				Price:    "$5,500/person",
+				Gear:     gearList(packageGear, "A bag for wet gear so it stays out of your clean clothes"),
+				Prep: []string{
+					"Forrest picks you up at Helena Regional Airport. Send your flight details when you book travel.",
+					"Lodging is arranged for you. Pack for six nights with laundry midweek if you need it.",
+					"If you fly with a firearm, declare it at check-in and bring a locking hard case.",
+				},
 			},
 		},
 	}
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/schema"
+)
+
+// BusinessID returns the @id of the outfitter's LocalBusiness node; trips
+// refer to it as their provider.
+func BusinessID() string {
+	return BaseURL() + "/#business"
+}
+
+// business is the outfitter as search engines see it. BusinessSchema fills
+// in the URLs, which depend on the configured base URL.
+var business = schema.Business{
+	Name:        "MT Hunt & Fish Outfitters",
+	Description: "Guided fishing and hunting trips out of Helena, Montana. Missouri River trout, elk, deer, bear, and antelope. 25 years of experience.",
+	Telephone:   "+1-406-459-5352",
+	PriceRange:  "$$-$$$",
+	Address: schema.Address{
+		Street:     "610 Jeanne Rd",
+		Locality:   "Helena",
+		Region:     "MT",
+		PostalCode: "59602",
+		Country:    "US",
+	},
+	Latitude:   46.5958,
+	Longitude:  -112.0270,
+	AreaServed: "Montana",
+}
+
+// BusinessSchema returns the LocalBusiness node, with testimonials as reviews.
+func BusinessSchema(testimonials []Testimonial) schema.Node {
+	b := business
+	b.ID = BusinessID()
+	b.URL = BaseURL()
+	b.Image = BaseURL() + "/static/img/hero/hero-montana-1600w.webp"
+	for _, t := range testimonials {
+		b.Reviews = append(b.Reviews, schema.Review{Author: t.Name, Body: t.Quote})
+	}
+	return schema.LocalBusiness(b)
+}
+
+// TripSchemas returns a TouristTrip node for each trip on the page at
+// pagePath. Call it after availability is attached: each date slot becomes an
+// offer whose availability follows the slot's status.
+func TripSchemas(trips []TripSection, touristType, pagePath string, now time.Time) []schema.Node {
+	nodes := make([]schema.Node, 0, len(trips))
+	for _, t := range trips {
+		nodes = append(nodes, schema.TouristTrip(schema.Trip{
+			Name:        t.Title,
+			Description: t.Description,
+			URL:         BaseURL() + pagePath + "#" + t.Slug,
+			Image:       ImageURL(t.Image, "800w"),
+			TouristType: touristType,
+			ProviderID:  BusinessID(),
+			Offers:      tripOffers(t, now),
+		}))
+	}
+	return nodes
+}
+
+func tripOffers(t TripSection, now time.Time) []schema.Offer {
+	base := schema.Offer{URL: BaseURL() + "/contact/?trip=" + t.Slug}
+	if price, ok := parsePrice(t.Price); ok {
+		base.Price = price
+		base.Currency = "USD"
+		base.Duration = parseDuration(t.Duration)
+	}
+	if len(t.Availability) == 0 {
+		if base.Price == 0 {
+			return nil
+		}
+		return []schema.Offer{base}
+	}
+
+	offers := make([]schema.Offer, 0, len(t.Availability))
+	for _, slot := range t.Availability {
+		o := base
+		o.Name = slot.Dates
+		o.Availability = slotAvailability(slot.Status)
+		if start, end, ok := ParseSlotDates(slot.Dates, now); ok {
+			o.Starts, o.Ends = start, end
+		}
+		offers = append(offers, o)
+	}
+	return offers
+}
+
+// slotAvailability maps a DateSlot status to a schema.org ItemAvailability.
+func slotAvailability(status string) string {
+	switch status {
+	case "booked":
+		return schema.SoldOut
+	case "limited":
+		return schema.LimitedAvailability
+	default:
+		return schema.InStock
+	}
+}
+
+var pricePattern = regexp.MustCompile(`\$([\d,]+(?:\.\d\d)?)`)
+
+// parsePrice reads the dollar amount from a display price like
+// "$3,500/person". "Contact for pricing" has none.
+func parsePrice(s string) (float64, bool) {
+	m := pricePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	return v, err == nil && v > 0
+}
+
+var durationPattern = regexp.MustCompile(`(?i)(\d+)(?:\s*[–-]\s*(\d+))?\s*(hrs?|hours?|days?)\b`)
+
+// parseDuration reads a display duration like "5–7 Days", "5 Days / 4
+// Nights" or "Full Day (8 hrs) or Half Day (4 hrs)" as a range in the unit
+// of its first figure. Nights are ignored; the days already cover them.
+func parseDuration(s string) *schema.Quantity {
+	var q *schema.Quantity
+	for _, m := range durationPattern.FindAllStringSubmatch(s, -1) {
+		unit := "DAY"
+		if strings.HasPrefix(strings.ToLower(m[3]), "h") {
+			unit = "HUR"
+		}
+		lo, _ := strconv.ParseFloat(m[1], 64)
+		hi := lo
+		if m[2] != "" {
+			hi, _ = strconv.ParseFloat(m[2], 64)
+		}
+		if q == nil {
+			q = &schema.Quantity{Min: lo, Max: hi, Unit: unit}
+			continue
+		}
+		if unit == q.Unit {
+			q.Min, q.Max = min(q.Min, lo), max(q.Max, hi)
+		}
+	}
+	return q
+}
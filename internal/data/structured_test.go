@@ -0,0 +1,73 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/firefly/packstring/internal/schema"
+)
+
+// TestSiteSchemaValid checks the structured data built from the site's own
+// content, since schema.Graph drops an invalid node with only a log line.
+func TestSiteSchemaValid(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	withSlots := func(trips []TripSection) []TripSection {
+		out := make([]TripSection, len(trips))
+		copy(out, trips)
+		for i := range out {
+			out[i].Availability = []DateSlot{
+				{Dates: "Jun 1 – Jun 15", Status: "open"},
+				{Dates: "Sept 16 – Sept 30", Status: "booked"},
+				{Dates: "Dec – Feb (Ice Fishing)", Status: "limited"},
+			}
+		}
+		return out
+	}
+
+	nodes := map[string]schema.Node{
+		"business":         BusinessSchema(nil),
+		"business+reviews": BusinessSchema(GetHomePageData(nil).Testimonials),
+		"breadcrumbs":      schema.BreadcrumbList(Breadcrumbs("Trips", "/trips/", "Fishing", "/trips/fishing/")),
+	}
+	for _, page := range []struct {
+		name  string
+		trips []TripSection
+	}{
+		{"fishing", GetFishingPageData().Trips},
+		{"hunting", GetHuntingPageData().Trips},
+		{"packages", GetPackagesPageData().Packages},
+	} {
+		for _, trips := range [][]TripSection{page.trips, withSlots(page.trips)} {
+			for i, n := range TripSchemas(trips, "Fishing", "/trips/"+page.name+"/", now) {
+				nodes[page.name+"/"+trips[i].Slug+"/"+strings.Repeat("+", len(trips[i].Availability))] = n
+			}
+		}
+	}
+
+	for name, n := range nodes {
+		if err := schema.Validate(n); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestSiteURLFollowsConfig(t *testing.T) {
+	defer SetSiteURL(SiteURL)
+	SetSiteURL("https://staging.example.com/")
+
+	if got := BaseURL(); got != "https://staging.example.com" {
+		t.Fatalf("BaseURL = %q", got)
+	}
+	b := BusinessSchema(nil)
+	if b["@id"] != "https://staging.example.com/#business" || b["url"] != "https://staging.example.com" {
+		t.Errorf("business @id, url = %v, %v", b["@id"], b["url"])
+	}
+	trip := TripSchemas(GetFishingPageData().Trips[:1], "Fishing", "/trips/fishing/", time.Now())[0]
+	if !strings.HasPrefix(trip["url"].(string), "https://staging.example.com/trips/fishing/#") {
+		t.Errorf("trip url = %v", trip["url"])
+	}
+	if crumbs := Breadcrumbs("Trips", "/trips/"); crumbs[1].URL != "https://staging.example.com/trips/" {
+		t.Errorf("breadcrumb = %v", crumbs[1].URL)
+	}
+}
@@ -1,5 +1,7 @@
 package data
 
+import "github.com/firefly/packstring/internal/posts"
+
 // TripSection represents a single trip or package offering rendered by the trip-section partial.
 type TripSection struct {
 	Title         string
@@ -10,10 +12,14 @@ type TripSection struct {
 	LocationLabel string   // "Waters", "Hunting Areas", "Destinations" — defaults to "Waters" in template
 	Locations     []string
 	Season        string // optional: e.g. "Sept 15 – Nov 25" for hunting
+	Licenses      []LicenseRequirement // hunting only: licenses and tags each hunter must hold
 	Includes      []string
 	Duration      string
 	Price         string
+	Gear          []string   // what to bring; shown on the trip page and in the pre-trip email
+	Prep          []string   // trip-prep notes: meeting spots, fitness, what's provided
 	Availability  []DateSlot // populated at render time from availability.yaml
+	Report        *posts.Post // latest fishing report for one of Locations, populated at render time
 }
 
 // FishingPageData holds all data rendered on the /trips/fishing/ page.
@@ -28,8 +34,9 @@ func GetFishingPageData() FishingPageData {
 		Meta: PageMeta{
 			Title:        "Fishing Trips — MT Hunt & Fish Outfitters",
 			Description:  "Guided fishing trips on the Missouri River, Canyon Ferry, Fort Peck, and more. Jet boat, drift boat, wade, and lake trips from Helena, Montana.",
-			CanonicalURL: SiteURL + "/trips/fishing/",
-			OGImage:      SiteURL + "/static/img/trips/fishing-card-800w.webp",
+			CanonicalURL: BaseURL() + "/trips/fishing/",
+			OGImage:      BaseURL() + "/static/img/trips/fishing-card-800w.webp",
+			Breadcrumbs:  Breadcrumbs("Trips", "/trips/", "Fishing", "/trips/fishing/"),
 		},
 		Trips: []TripSection{
 			{
@@ -49,6 +56,12 @@ func GetFishingPageData() FishingPageData {
 				},
 				Duration: "Full Day (8 hrs) or Half Day (4 hrs)",
 				Price:    "$500/person",
+				Gear:     gearList(fishingGear, "Warm jacket and gloves: the jet boat runs fast and the wind off the water is cold"),
+				Prep: []string{
+					"Meet at the Craig boat ramp 30 minutes before launch. Forrest will text the exact time the night before.",
+					"Rods, reels, flies and tackle are on the boat. Bring your own rod if you like fishing it.",
+					"Eat breakfast before you come. Lunch is on the boat for full-day trips.",
+				},
 			},
 			{
 				Title:   "Drift Boat Trips",
@@ -67,6 +80,12 @@ func GetFishingPageData() FishingPageData {
 				},
 				Duration: "Full Day (8 hrs) or Half Day (4 hrs)",
 				Price:    "$500/person",
+				Gear:     gearList(fishingGear, "Quick-dry pants or shorts and sandals or boots you can get wet"),
+				Prep: []string{
+					"Meeting spot depends on the river. Forrest will confirm it the week before based on conditions.",
+					"Drift boats have limited space. Pack light in a soft bag, no hard coolers.",
+					"Rods, reels, flies and tackle are provided.",
+				},
 			},
 			{
 				Title:   "Lake Trips",
@@ -85,6 +104,12 @@ func GetFishingPageData() FishingPageData {
 				},
 				Duration: "Full Day (8 hrs) or Half Day (4 hrs)",
 				Price:    "$450/person",
+				Gear:     gearList(fishingGear, "Snacks the kids will actually eat"),
+				Prep: []string{
+					"Meet at the marina named in your confirmation. Forrest launches early to beat the afternoon wind.",
+					"Life jackets are on the boat in adult and kid sizes.",
+					"Fish are cleaned and bagged at the dock. Bring a cooler with ice if you plan to keep them.",
+				},
 			},
 			{
 				Title:   "Wade Trips",
@@ -102,6 +127,12 @@ func GetFishingPageData() FishingPageData {
 				},
 				Duration: "Full Day (8 hrs) or Half Day (4 hrs)",
 				Price:    "$400/person",
+				Gear:     gearList(fishingGear, "Wool or synthetic socks (waders and boots are provided if you need them)", "Small daypack for water and layers"),
+				Prep: []string{
+					"Expect to walk a mile or more over uneven ground to reach the water.",
+					"Tell us your wader and boot sizes when you confirm so we bring the right ones.",
+					"Rods, reels, flies and tackle are provided.",
+				},
 			},
 			{
 				Title:   "Specialty Trips",
@@ -120,6 +151,11 @@ func GetFishingPageData() FishingPageData {
 				},
 				Duration: "Full Day (8 hrs)",
 				Price:    "$450/person",
+				Gear:     gearList(fishingGear, "Insulated boots, warm gloves and a face mask for winter ice trips"),
+				Prep: []string{
+					"Target species and meeting spot depend on the season. Forrest will call to plan the day.",
+					"Ice trips run in a heated shelter, but you will still be out on the ice getting there.",
+				},
 			},
 		},
 	}
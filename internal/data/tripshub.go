@@ -12,8 +12,9 @@ func GetTripsHubData() TripsHubData {
 		Meta: PageMeta{
 			Title:        "Guided Trips — MT Hunt & Fish Outfitters",
 			Description:  "Fishing and hunting trips from Helena, Montana. Jet boat, drift boat, wade, and lake fishing. Elk, deer, bear, and antelope hunts. Multi-day packages.",
-			CanonicalURL: SiteURL + "/trips/",
-			OGImage:      SiteURL + "/static/img/hero/hero-montana-1600w.webp",
+			CanonicalURL: BaseURL() + "/trips/",
+			OGImage:      BaseURL() + "/static/img/hero/hero-montana-1600w.webp",
+			Breadcrumbs:  Breadcrumbs("Trips", "/trips/"),
 		},
 		TripCards: []TripCard{
 			{
@@ -0,0 +1,46 @@
+package data
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	parenthetical = regexp.MustCompile(`\s*\(.*?\)`)
+	waterSuffix   = regexp.MustCompile(`\s+(river|lake|reservoir)$`)
+)
+
+// waterKey normalizes a water name so "Canyon Ferry", "Canyon Ferry Reservoir"
+// and "Missouri River (Craig to Cascade)" compare by the water itself.
+func waterKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(parenthetical.ReplaceAllString(name, "")))
+	return waterSuffix.ReplaceAllString(key, "")
+}
+
+// SameWater reports whether two location names refer to the same river or lake.
+func SameWater(a, b string) bool {
+	ka := waterKey(a)
+	return ka != "" && ka == waterKey(b)
+}
+
+// Waters lists the named rivers and lakes the fishing trips run on, for the
+// report editor's river picker.
+func Waters() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range GetFishingPageData().Trips {
+		for _, loc := range t.Locations {
+			if strings.HasPrefix(loc, "Various") {
+				continue
+			}
+			name := strings.TrimSpace(parenthetical.ReplaceAllString(loc, ""))
+			if key := waterKey(name); !seen[key] {
+				seen[key] = true
+				out = append(out, name)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// AnalyticsCount is page views and unique visitors to add to one day's
+// rollup for a key, e.g. kind "page", key "/trips/fishing/".
+type AnalyticsCount struct {
+	Day      string // YYYY-MM-DD, UTC
+	Kind     string // site, page, referrer, device, trip_view, trip_contact
+	Key      string
+	Views    int
+	Visitors int
+}
+
+// AnalyticsRow is a key's totals over a period.
+type AnalyticsRow struct {
+	Key      string
+	Views    int
+	Visitors int // unique per day, summed over the days
+}
+
+// AnalyticsDay is the whole site's totals for one day.
+type AnalyticsDay struct {
+	Day      time.Time
+	Views    int
+	Visitors int
+}
+
+// analyticsDay formats t as the day column stores it.
+func analyticsDay(t time.Time) string {
+	return t.UTC().Format(DateLayout)
+}
+
+// AddAnalytics adds counts to the daily rollups in one transaction.
+func (s *Store) AddAnalytics(counts []AnalyticsCount) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("add analytics: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO analytics_daily (day, kind, key, views, visitors) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (day, kind, key) DO UPDATE SET
+			views = views + excluded.views,
+			visitors = visitors + excluded.visitors`)
+	if err != nil {
+		return fmt.Errorf("add analytics: %w", err)
+	}
+	defer stmt.Close()
+	for _, c := range counts {
+		if _, err := stmt.Exec(c.Day, c.Kind, c.Key, c.Views, c.Visitors); err != nil {
+			return fmt.Errorf("add analytics %s %q: %w", c.Kind, c.Key, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// AnalyticsTop returns the keys of a kind with the most views since the
+// given day, up to limit (0 for all).
+func (s *Store) AnalyticsTop(kind string, since time.Time, limit int) ([]AnalyticsRow, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(`
+		SELECT key, SUM(views), SUM(visitors) FROM analytics_daily
+		WHERE kind = ? AND day >= ?
+		GROUP BY key ORDER BY SUM(views) DESC, key LIMIT ?`,
+		kind, analyticsDay(since), limit)
+	if err != nil {
+		return nil, fmt.Errorf("analytics top %s: %w", kind, err)
+	}
+	defer rows.Close()
+
+	var out []AnalyticsRow
+	for rows.Next() {
+		var r AnalyticsRow
+		if err := rows.Scan(&r.Key, &r.Views, &r.Visitors); err != nil {
+			return nil, fmt.Errorf("scan analytics row: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// AnalyticsDays returns the site's daily totals since the given day, oldest
+// first. Days without views are left out.
+func (s *Store) AnalyticsDays(since time.Time) ([]AnalyticsDay, error) {
+	rows, err := s.db.Query(`
+		SELECT day, views, visitors FROM analytics_daily
+		WHERE kind = 'site' AND day >= ? ORDER BY day`,
+		analyticsDay(since))
+	if err != nil {
+		return nil, fmt.Errorf("analytics days: %w", err)
+	}
+	defer rows.Close()
+
+	var out []AnalyticsDay
+	for rows.Next() {
+		var d AnalyticsDay
+		if err := rows.Scan(&d.Day, &d.Views, &d.Visitors); err != nil {
+			return nil, fmt.Errorf("scan analytics day: %w", err)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// InquiriesByTrip counts inquiries per trip slug received since the given
+// time, leaving out spam.
+func (s *Store) InquiriesByTrip(since time.Time) (map[string]int, error) {
+	rows, err := s.db.Query(`
+		SELECT trip_slug, COUNT(*) FROM inquiries
+		WHERE status != 'spam' AND trip_slug != '' AND created_at >= ?
+		GROUP BY trip_slug`,
+		since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("inquiries by trip: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var slug string
+		var n int
+		if err := rows.Scan(&slug, &n); err != nil {
+			return nil, fmt.Errorf("scan inquiries by trip: %w", err)
+		}
+		counts[slug] = n
+	}
+	return counts, rows.Err()
+}
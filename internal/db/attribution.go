@@ -0,0 +1,70 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Attribution is where a client came from on their first visit, as
+// captured by the site's source cookie.
+type Attribution struct {
+	Source      string // e.g. "google", "facebook", "sports-show"; "(direct)" for typed-in visits
+	Medium      string // e.g. "organic", "cpc", "social", "referral", "print"
+	Campaign    string // utm_campaign
+	Term        string // utm_term
+	Content     string // utm_content
+	Referrer    string // the external page that linked to the site, if any
+	LandingPage string // path and query of the first page seen
+}
+
+// SourceStat is one row of the attribution report: what inquiries from a
+// source, medium and campaign turned into.
+type SourceStat struct {
+	Source       string
+	Medium       string
+	Campaign     string
+	Inquiries    int
+	Bookings     int   // booked, or paid a deposit
+	DepositCents int64 // paid deposits, refunds excluded
+}
+
+// BookingRate returns the share of inquiries that booked, as a whole percent.
+func (s SourceStat) BookingRate() int {
+	if s.Inquiries == 0 {
+		return 0
+	}
+	return s.Bookings * 100 / s.Inquiries
+}
+
+// SourceReport totals inquiries, bookings and paid deposits by source,
+// medium and campaign for inquiries received since the given time (zero
+// for all time). Spam is left out. Rows are ordered by inquiry count.
+func (s *Store) SourceReport(since time.Time) ([]SourceStat, error) {
+	rows, err := s.db.Query(`
+		SELECT i.source, i.medium, i.campaign,
+			COUNT(*),
+			SUM(CASE WHEN i.status = 'booked' OR p.cents IS NOT NULL THEN 1 ELSE 0 END),
+			COALESCE(SUM(p.cents), 0)
+		FROM inquiries i
+		LEFT JOIN (
+			SELECT inquiry_id, SUM(amount_cents) AS cents FROM payments WHERE status = 'paid' GROUP BY inquiry_id
+		) p ON p.inquiry_id = i.id
+		WHERE i.status != 'spam' AND i.created_at >= ?
+		GROUP BY i.source, i.medium, i.campaign
+		ORDER BY COUNT(*) DESC, i.source, i.medium, i.campaign`,
+		since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("source report: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []SourceStat
+	for rows.Next() {
+		var st SourceStat
+		if err := rows.Scan(&st.Source, &st.Medium, &st.Campaign, &st.Inquiries, &st.Bookings, &st.DepositCents); err != nil {
+			return nil, fmt.Errorf("scan source stat: %w", err)
+		}
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}
@@ -1,14 +1,28 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
 
+// DateLayout is the format used for calendar dates stored in DATE columns.
+const DateLayout = "2006-01-02"
+
+// dateArg converts an optional date into a query argument, storing it as a
+// plain YYYY-MM-DD string so range comparisons in SQL stay lexicographic.
+func dateArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format(DateLayout)
+}
+
 // Store wraps the SQLite database connection.
 type Store struct {
 	db *sql.DB
@@ -50,6 +64,15 @@ func Open(path string) (*Store, error) {
 	return s, nil
 }
 
+// Ping checks that the database answers a query, for readiness checks.
+func (s *Store) Ping(ctx context.Context) error {
+	var one int
+	if err := s.db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("db: ping: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection.
 func (s *Store) Close() error {
 	return s.db.Close()
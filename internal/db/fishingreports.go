@@ -0,0 +1,212 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FishingReport is a report written in the admin, with the river conditions
+// it describes.
+type FishingReport struct {
+	ID          int64
+	Slug        string
+	Title       string
+	River       string
+	FlowCFS     *int
+	WaterTempF  *int
+	Hatches     []string
+	Flies       []string
+	Body        string // Markdown
+	Status      string // draft, published
+	PublishedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Live reports whether the report is published and its publish time has
+// arrived.
+func (r *FishingReport) Live(now time.Time) bool {
+	return r.Status == "published" && r.PublishedAt != nil && !r.PublishedAt.After(now)
+}
+
+// Scheduled reports whether the report is published with a future date.
+func (r *FishingReport) Scheduled(now time.Time) bool {
+	return r.Status == "published" && r.PublishedAt != nil && r.PublishedAt.After(now)
+}
+
+const fishingReportColumns = `id, slug, title, river, flow_cfs, water_temp_f, hatches, flies, body, status, published_at, created_at, updated_at`
+
+func scanFishingReport(row rowScanner, r *FishingReport) error {
+	var flow, temp sql.NullInt64
+	var hatches, flies string
+	var published sql.NullTime
+	if err := row.Scan(&r.ID, &r.Slug, &r.Title, &r.River, &flow, &temp, &hatches, &flies, &r.Body, &r.Status, &published, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return err
+	}
+	r.FlowCFS, r.WaterTempF, r.PublishedAt = nil, nil, nil
+	if flow.Valid {
+		v := int(flow.Int64)
+		r.FlowCFS = &v
+	}
+	if temp.Valid {
+		v := int(temp.Int64)
+		r.WaterTempF = &v
+	}
+	if published.Valid {
+		r.PublishedAt = &published.Time
+	}
+	r.Hatches = splitLines(hatches)
+	r.Flies = splitLines(flies)
+	return nil
+}
+
+func scanFishingReports(rows *sql.Rows) ([]FishingReport, error) {
+	defer rows.Close()
+	var out []FishingReport
+	for rows.Next() {
+		var r FishingReport
+		if err := scanFishingReport(rows, &r); err != nil {
+			return nil, fmt.Errorf("scan fishing report: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// splitLines splits newline-separated text, dropping blank lines.
+func splitLines(s string) []string {
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+var slugUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify lowercases s and joins its words with hyphens for use in a URL.
+func Slugify(s string) string {
+	return strings.Trim(slugUnsafe.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// ListFishingReports returns every report, drafts included, newest first.
+func (s *Store) ListFishingReports() ([]FishingReport, error) {
+	rows, err := s.db.Query(`SELECT ` + fishingReportColumns + ` FROM fishing_reports
+		ORDER BY COALESCE(published_at, updated_at) DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list fishing reports: %w", err)
+	}
+	return scanFishingReports(rows)
+}
+
+// PublishedFishingReports returns reports live at the given time, newest first.
+func (s *Store) PublishedFishingReports(now time.Time) ([]FishingReport, error) {
+	rows, err := s.db.Query(`SELECT `+fishingReportColumns+` FROM fishing_reports
+		WHERE status = 'published' AND published_at <= ?
+		ORDER BY published_at DESC, id DESC`, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("published fishing reports: %w", err)
+	}
+	return scanFishingReports(rows)
+}
+
+// GetFishingReport returns a single report by ID.
+func (s *Store) GetFishingReport(id int64) (*FishingReport, error) {
+	r := &FishingReport{}
+	err := scanFishingReport(s.db.QueryRow(`SELECT `+fishingReportColumns+` FROM fishing_reports WHERE id = ?`, id), r)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get fishing report %d: %w", id, err)
+	}
+	return r, nil
+}
+
+// GetFishingReportBySlug returns a single report by its URL slug.
+func (s *Store) GetFishingReportBySlug(slug string) (*FishingReport, error) {
+	r := &FishingReport{}
+	err := scanFishingReport(s.db.QueryRow(`SELECT `+fishingReportColumns+` FROM fishing_reports WHERE slug = ?`, slug), r)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get fishing report %q: %w", slug, err)
+	}
+	return r, nil
+}
+
+// SaveFishingReport inserts a new report (ID 0) or updates an existing one.
+// An empty slug is derived from the title.
+func (s *Store) SaveFishingReport(r *FishingReport) (int64, error) {
+	r.Title = strings.TrimSpace(r.Title)
+	r.River = strings.TrimSpace(r.River)
+	if r.Title == "" {
+		return 0, fmt.Errorf("title is required")
+	}
+	if r.River == "" {
+		return 0, fmt.Errorf("river is required")
+	}
+	if r.Status != "draft" && r.Status != "published" {
+		return 0, fmt.Errorf("invalid status: %s", r.Status)
+	}
+	if r.Status == "published" && r.PublishedAt == nil {
+		now := time.Now()
+		r.PublishedAt = &now
+	}
+	r.Slug = Slugify(r.Slug)
+	if r.Slug == "" {
+		r.Slug = Slugify(r.Title)
+	}
+	if r.Slug == "" {
+		return 0, fmt.Errorf("slug is required")
+	}
+
+	var existing int64
+	err := s.db.QueryRow(`SELECT id FROM fishing_reports WHERE slug = ? AND id != ?`, r.Slug, r.ID).Scan(&existing)
+	if err == nil {
+		return 0, fmt.Errorf("another report already uses the slug %q", r.Slug)
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("check slug: %w", err)
+	}
+
+	var published any
+	if r.PublishedAt != nil {
+		published = r.PublishedAt.UTC()
+	}
+	args := []any{r.Slug, r.Title, r.River, r.FlowCFS, r.WaterTempF,
+		strings.Join(r.Hatches, "\n"), strings.Join(r.Flies, "\n"), r.Body, r.Status, published}
+
+	if r.ID == 0 {
+		res, err := s.db.Exec(`
+			INSERT INTO fishing_reports (slug, title, river, flow_cfs, water_temp_f, hatches, flies, body, status, published_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, args...)
+		if err != nil {
+			return 0, fmt.Errorf("create fishing report: %w", err)
+		}
+		return res.LastInsertId()
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE fishing_reports SET slug = ?, title = ?, river = ?, flow_cfs = ?, water_temp_f = ?,
+			hatches = ?, flies = ?, body = ?, status = ?, published_at = ?, updated_at = datetime('now')
+		WHERE id = ?`, append(args, r.ID)...)
+	if err != nil {
+		return 0, fmt.Errorf("update fishing report %d: %w", r.ID, err)
+	}
+	return r.ID, nil
+}
+
+// DeleteFishingReport removes a report.
+func (s *Store) DeleteFishingReport(id int64) error {
+	if _, err := s.db.Exec(`DELETE FROM fishing_reports WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete fishing report %d: %w", id, err)
+	}
+	return nil
+}
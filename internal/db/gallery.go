@@ -0,0 +1,181 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GalleryCategories are the filter tabs on the gallery page, in order. The
+// gallery_photos table only accepts these slugs.
+var GalleryCategories = []string{"fishing", "hunting", "scenery", "camp"}
+
+// GalleryPhoto is a photo shown on /gallery/. Its files live on disk under
+// Name; see the gallery package.
+type GalleryPhoto struct {
+	ID        int64
+	Name      string // file base name, e.g. "gallery-07"
+	Alt       string
+	Caption   string
+	Category  string
+	Position  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const galleryPhotoColumns = `id, name, alt, caption, category, position, created_at, updated_at`
+
+func scanGalleryPhoto(row rowScanner, p *GalleryPhoto) error {
+	return row.Scan(&p.ID, &p.Name, &p.Alt, &p.Caption, &p.Category, &p.Position, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// validateGalleryPhoto trims the editable fields and checks them.
+func validateGalleryPhoto(p *GalleryPhoto) error {
+	p.Alt = strings.TrimSpace(p.Alt)
+	p.Caption = strings.TrimSpace(p.Caption)
+	if p.Alt == "" {
+		return fmt.Errorf("alt text is required")
+	}
+	for _, c := range GalleryCategories {
+		if p.Category == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid category: %s", p.Category)
+}
+
+// ListGalleryPhotos returns every photo in display order.
+func (s *Store) ListGalleryPhotos() ([]GalleryPhoto, error) {
+	rows, err := s.db.Query(`SELECT ` + galleryPhotoColumns + ` FROM gallery_photos ORDER BY position, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list gallery photos: %w", err)
+	}
+	defer rows.Close()
+	var out []GalleryPhoto
+	for rows.Next() {
+		var p GalleryPhoto
+		if err := scanGalleryPhoto(rows, &p); err != nil {
+			return nil, fmt.Errorf("scan gallery photo: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// GetGalleryPhoto returns a single photo by ID.
+func (s *Store) GetGalleryPhoto(id int64) (*GalleryPhoto, error) {
+	p := &GalleryPhoto{}
+	err := scanGalleryPhoto(s.db.QueryRow(`SELECT `+galleryPhotoColumns+` FROM gallery_photos WHERE id = ?`, id), p)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get gallery photo %d: %w", id, err)
+	}
+	return p, nil
+}
+
+// CreateGalleryPhoto adds a photo at the end of the gallery and names it
+// after its ID, e.g. "gallery-19", filling in p.ID, p.Name and p.Position.
+func (s *Store) CreateGalleryPhoto(p *GalleryPhoto) error {
+	if err := validateGalleryPhoto(p); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(position), 0) + 1 FROM gallery_photos`).Scan(&p.Position); err != nil {
+		return fmt.Errorf("next gallery position: %w", err)
+	}
+	// Insert under a placeholder name, then rename once the ID is known.
+	res, err := tx.Exec(`INSERT INTO gallery_photos (name, alt, caption, category, position) VALUES (?, ?, ?, ?, ?)`,
+		fmt.Sprintf("pending-%d", time.Now().UnixNano()), p.Alt, p.Caption, p.Category, p.Position)
+	if err != nil {
+		return fmt.Errorf("create gallery photo: %w", err)
+	}
+	if p.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("create gallery photo: %w", err)
+	}
+	p.Name = fmt.Sprintf("gallery-%02d", p.ID)
+	if _, err := tx.Exec(`UPDATE gallery_photos SET name = ? WHERE id = ?`, p.Name, p.ID); err != nil {
+		return fmt.Errorf("name gallery photo %d: %w", p.ID, err)
+	}
+	return tx.Commit()
+}
+
+// UpdateGalleryPhoto saves a photo's alt text, caption and category.
+func (s *Store) UpdateGalleryPhoto(p *GalleryPhoto) error {
+	if err := validateGalleryPhoto(p); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`UPDATE gallery_photos SET alt = ?, caption = ?, category = ?, updated_at = datetime('now') WHERE id = ?`,
+		p.Alt, p.Caption, p.Category, p.ID)
+	if err != nil {
+		return fmt.Errorf("update gallery photo %d: %w", p.ID, err)
+	}
+	return nil
+}
+
+// MoveGalleryPhoto swaps a photo with its neighbour: earlier in the gallery
+// when up is true, later otherwise. Moving past either end, or moving a
+// photo that no longer exists, does nothing.
+func (s *Store) MoveGalleryPhoto(id int64, up bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	var pos int
+	err = tx.QueryRow(`SELECT position FROM gallery_photos WHERE id = ?`, id).Scan(&pos)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get gallery photo %d: %w", id, err)
+	}
+	query := `SELECT id, position FROM gallery_photos WHERE position > ? ORDER BY position LIMIT 1`
+	if up {
+		query = `SELECT id, position FROM gallery_photos WHERE position < ? ORDER BY position DESC LIMIT 1`
+	}
+	var otherID int64
+	var otherPos int
+	err = tx.QueryRow(query, pos).Scan(&otherID, &otherPos)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("find neighbour of gallery photo %d: %w", id, err)
+	}
+	if _, err := tx.Exec(`UPDATE gallery_photos SET position = ?, updated_at = datetime('now') WHERE id = ?`, otherPos, id); err != nil {
+		return fmt.Errorf("move gallery photo %d: %w", id, err)
+	}
+	if _, err := tx.Exec(`UPDATE gallery_photos SET position = ?, updated_at = datetime('now') WHERE id = ?`, pos, otherID); err != nil {
+		return fmt.Errorf("move gallery photo %d: %w", otherID, err)
+	}
+	return tx.Commit()
+}
+
+// DeleteGalleryPhoto removes a photo's row. The caller removes its files.
+func (s *Store) DeleteGalleryPhoto(id int64) error {
+	if _, err := s.db.Exec(`DELETE FROM gallery_photos WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete gallery photo %d: %w", id, err)
+	}
+	return nil
+}
+
+// GalleryModTime returns when the gallery last changed. Deletions aren't
+// tracked, so removing a photo doesn't move it.
+func (s *Store) GalleryModTime() (time.Time, error) {
+	var t sql.NullTime
+	// MAX() loses the column's declared type, so scan the row it picks.
+	err := s.db.QueryRow(`SELECT updated_at FROM gallery_photos ORDER BY updated_at DESC LIMIT 1`).Scan(&t)
+	if err != nil && err != sql.ErrNoRows {
+		return time.Time{}, fmt.Errorf("gallery mod time: %w", err)
+	}
+	return t.Time, nil
+}
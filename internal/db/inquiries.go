@@ -3,33 +3,121 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// MaxPartySize caps how many people a single inquiry tracks waivers, guests,
+// and licenses for.
+const MaxPartySize = 20
+
 // Inquiry represents a contact form submission.
 type Inquiry struct {
-	ID         int64
-	Name       string
-	Email      string
-	Phone      string
-	TripSlug   string
-	TripName   string
-	Dates      string
-	PartySize  string
-	Experience string
-	Message    string
-	Status     string // new, contacted, booked, archived
-	Notes      string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID          int64
+	Name        string
+	Email       string
+	Phone       string
+	TripSlug    string
+	TripName    string
+	Dates       string
+	PartySize   string
+	Experience  string
+	Message     string
+	Status      string // new, contacted, booked, archived, spam
+	Notes       string
+	SpamReasons string      // why the spam scorer quarantined it, one per line
+	Attribution Attribution // where the client first came from
+	TripStart   *time.Time  // confirmed first day of the trip, set by the admin
+	TripEnd     *time.Time  // confirmed last day of the trip (inclusive)
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Headcount reads the leading number from the free-text party size, falling
+// back to 1 when it can't tell.
+func (i *Inquiry) Headcount() int {
+	digits := strings.TrimLeftFunc(i.PartySize, func(r rune) bool { return r < '0' || r > '9' })
+	end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		digits = digits[:end]
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 1 {
+		return 1
+	}
+	if n > MaxPartySize {
+		return MaxPartySize
+	}
+	return n
+}
+
+// inquiryColumns is the column list shared by every query that scans a full Inquiry.
+const inquiryColumns = `id, name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, notes, spam_reasons, trip_start, trip_end, created_at, updated_at,
+	source, medium, campaign, term, content, referrer, landing_page`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanInquiry reads a row selected with inquiryColumns into inq.
+func scanInquiry(row rowScanner, inq *Inquiry) error {
+	var tripStart, tripEnd sql.NullTime
+	a := &inq.Attribution
+	if err := row.Scan(&inq.ID, &inq.Name, &inq.Email, &inq.Phone, &inq.TripSlug, &inq.TripName, &inq.Dates, &inq.PartySize, &inq.Experience, &inq.Message, &inq.Status, &inq.Notes, &inq.SpamReasons, &tripStart, &tripEnd, &inq.CreatedAt, &inq.UpdatedAt,
+		&a.Source, &a.Medium, &a.Campaign, &a.Term, &a.Content, &a.Referrer, &a.LandingPage); err != nil {
+		return err
+	}
+	inq.TripStart, inq.TripEnd = nil, nil
+	if tripStart.Valid {
+		inq.TripStart = &tripStart.Time
+	}
+	if tripEnd.Valid {
+		inq.TripEnd = &tripEnd.Time
+	}
+	return nil
+}
+
+// scanInquiries drains rows selected with inquiryColumns.
+func scanInquiries(rows *sql.Rows) ([]Inquiry, error) {
+	defer rows.Close()
+
+	var inquiries []Inquiry
+	for rows.Next() {
+		var inq Inquiry
+		if err := scanInquiry(rows, &inq); err != nil {
+			return nil, fmt.Errorf("scan inquiry: %w", err)
+		}
+		inquiries = append(inquiries, inq)
+	}
+	return inquiries, rows.Err()
+}
+
+// Statuses an inquiry can have. Spam is a quarantine: those inquiries are
+// left out of the default list and counts until the admin releases them.
+var inquiryStatuses = map[string]bool{"new": true, "contacted": true, "booked": true, "archived": true, "spam": true}
+
+// initialStatus is the status a submitted inquiry is stored with: spam if
+// the scorer flagged it, otherwise new.
+func initialStatus(inq *Inquiry) string {
+	if inq.Status == "spam" {
+		return "spam"
+	}
+	return "new"
 }
 
-// CreateInquiry inserts a new inquiry and returns its ID.
+// CreateInquiry inserts a new inquiry and returns its ID. It is stored as
+// new unless inq.Status is spam, in which case SpamReasons is kept with it.
 func (s *Store) CreateInquiry(inq *Inquiry) (int64, error) {
+	a := inq.Attribution
 	res, err := s.db.Exec(`
-		INSERT INTO inquiries (name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'new')`,
+		INSERT INTO inquiries (name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, spam_reasons,
+			source, medium, campaign, term, content, referrer, landing_page)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		inq.Name, inq.Email, inq.Phone, inq.TripSlug, inq.TripName, inq.Dates, inq.PartySize, inq.Experience, inq.Message,
+		initialStatus(inq), inq.SpamReasons,
+		a.Source, a.Medium, a.Campaign, a.Term, a.Content, a.Referrer, a.LandingPage,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("create inquiry: %w", err)
@@ -37,13 +125,37 @@ func (s *Store) CreateInquiry(inq *Inquiry) (int64, error) {
 	return res.LastInsertId()
 }
 
+// RestoreInquiry inserts an inquiry that was received earlier, keeping its
+// original CreatedAt. It is idempotent: if an inquiry with the same email,
+// message and creation time exists, nothing is inserted and 0 is returned.
+func (s *Store) RestoreInquiry(inq *Inquiry) (int64, error) {
+	created := inq.CreatedAt.Unix()
+	a := inq.Attribution
+	res, err := s.db.Exec(`
+		INSERT INTO inquiries (name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, spam_reasons,
+			source, medium, campaign, term, content, referrer, landing_page, created_at, updated_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime(?, 'unixepoch'), datetime(?, 'unixepoch')
+		WHERE NOT EXISTS (
+			SELECT 1 FROM inquiries WHERE email = ? AND message = ? AND created_at = datetime(?, 'unixepoch')
+		)`,
+		inq.Name, inq.Email, inq.Phone, inq.TripSlug, inq.TripName, inq.Dates, inq.PartySize, inq.Experience, inq.Message,
+		initialStatus(inq), inq.SpamReasons,
+		a.Source, a.Medium, a.Campaign, a.Term, a.Content, a.Referrer, a.LandingPage,
+		created, created, inq.Email, inq.Message, created,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("restore inquiry: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, nil
+	}
+	return res.LastInsertId()
+}
+
 // GetInquiry returns a single inquiry by ID.
 func (s *Store) GetInquiry(id int64) (*Inquiry, error) {
 	inq := &Inquiry{}
-	err := s.db.QueryRow(`
-		SELECT id, name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, notes, created_at, updated_at
-		FROM inquiries WHERE id = ?`, id,
-	).Scan(&inq.ID, &inq.Name, &inq.Email, &inq.Phone, &inq.TripSlug, &inq.TripName, &inq.Dates, &inq.PartySize, &inq.Experience, &inq.Message, &inq.Status, &inq.Notes, &inq.CreatedAt, &inq.UpdatedAt)
+	err := scanInquiry(s.db.QueryRow(`SELECT `+inquiryColumns+` FROM inquiries WHERE id = ?`, id), inq)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -53,40 +165,26 @@ func (s *Store) GetInquiry(id int64) (*Inquiry, error) {
 	return inq, nil
 }
 
-// ListInquiries returns inquiries filtered by status (empty string = all), ordered by newest first.
+// ListInquiries returns inquiries filtered by status (empty string = all
+// but spam), ordered by newest first.
 func (s *Store) ListInquiries(status string) ([]Inquiry, error) {
 	var rows *sql.Rows
 	var err error
 
 	if status != "" {
-		rows, err = s.db.Query(`
-			SELECT id, name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, notes, created_at, updated_at
-			FROM inquiries WHERE status = ? ORDER BY created_at DESC`, status)
+		rows, err = s.db.Query(`SELECT `+inquiryColumns+` FROM inquiries WHERE status = ? ORDER BY created_at DESC`, status)
 	} else {
-		rows, err = s.db.Query(`
-			SELECT id, name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, notes, created_at, updated_at
-			FROM inquiries ORDER BY created_at DESC`)
+		rows, err = s.db.Query(`SELECT ` + inquiryColumns + ` FROM inquiries WHERE status != 'spam' ORDER BY created_at DESC`)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("list inquiries: %w", err)
 	}
-	defer rows.Close()
-
-	var inquiries []Inquiry
-	for rows.Next() {
-		var inq Inquiry
-		if err := rows.Scan(&inq.ID, &inq.Name, &inq.Email, &inq.Phone, &inq.TripSlug, &inq.TripName, &inq.Dates, &inq.PartySize, &inq.Experience, &inq.Message, &inq.Status, &inq.Notes, &inq.CreatedAt, &inq.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("scan inquiry: %w", err)
-		}
-		inquiries = append(inquiries, inq)
-	}
-	return inquiries, rows.Err()
+	return scanInquiries(rows)
 }
 
 // UpdateInquiryStatus sets the status and updated_at for an inquiry.
 func (s *Store) UpdateInquiryStatus(id int64, status string) error {
-	valid := map[string]bool{"new": true, "contacted": true, "booked": true, "archived": true}
-	if !valid[status] {
+	if !inquiryStatuses[status] {
 		return fmt.Errorf("invalid status: %s", status)
 	}
 	_, err := s.db.Exec(`UPDATE inquiries SET status = ?, updated_at = datetime('now') WHERE id = ?`, status, id)
@@ -105,14 +203,32 @@ func (s *Store) UpdateInquiryNotes(id int64, notes string) error {
 	return nil
 }
 
-// CountInquiries returns the count of inquiries matching a status (empty = all).
+// UpdateInquiryDates sets the confirmed trip dates for an inquiry. Passing nil
+// for both clears them and takes the booking off the schedule.
+func (s *Store) UpdateInquiryDates(id int64, start, end *time.Time) error {
+	if (start == nil) != (end == nil) {
+		return fmt.Errorf("trip start and end must both be set or both be empty")
+	}
+	if start != nil && end.Before(*start) {
+		return fmt.Errorf("trip end %s is before start %s", end.Format(DateLayout), start.Format(DateLayout))
+	}
+	_, err := s.db.Exec(`UPDATE inquiries SET trip_start = ?, trip_end = ?, updated_at = datetime('now') WHERE id = ?`,
+		dateArg(start), dateArg(end), id)
+	if err != nil {
+		return fmt.Errorf("update inquiry dates: %w", err)
+	}
+	return nil
+}
+
+// CountInquiries returns the count of inquiries matching a status (empty =
+// all but spam).
 func (s *Store) CountInquiries(status string) (int, error) {
 	var count int
 	var err error
 	if status != "" {
 		err = s.db.QueryRow("SELECT COUNT(*) FROM inquiries WHERE status = ?", status).Scan(&count)
 	} else {
-		err = s.db.QueryRow("SELECT COUNT(*) FROM inquiries").Scan(&count)
+		err = s.db.QueryRow("SELECT COUNT(*) FROM inquiries WHERE status != 'spam'").Scan(&count)
 	}
 	if err != nil {
 		return 0, fmt.Errorf("count inquiries: %w", err)
@@ -120,23 +236,11 @@ func (s *Store) CountInquiries(status string) (int, error) {
 	return count, nil
 }
 
-// RecentInquiries returns the N most recent inquiries.
+// RecentInquiries returns the N most recent inquiries, leaving out spam.
 func (s *Store) RecentInquiries(limit int) ([]Inquiry, error) {
-	rows, err := s.db.Query(`
-		SELECT id, name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, notes, created_at, updated_at
-		FROM inquiries ORDER BY created_at DESC LIMIT ?`, limit)
+	rows, err := s.db.Query(`SELECT `+inquiryColumns+` FROM inquiries WHERE status != 'spam' ORDER BY created_at DESC LIMIT ?`, limit)
 	if err != nil {
 		return nil, fmt.Errorf("recent inquiries: %w", err)
 	}
-	defer rows.Close()
-
-	var inquiries []Inquiry
-	for rows.Next() {
-		var inq Inquiry
-		if err := rows.Scan(&inq.ID, &inq.Name, &inq.Email, &inq.Phone, &inq.TripSlug, &inq.TripName, &inq.Dates, &inq.PartySize, &inq.Experience, &inq.Message, &inq.Status, &inq.Notes, &inq.CreatedAt, &inq.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("scan inquiry: %w", err)
-		}
-		inquiries = append(inquiries, inq)
-	}
-	return inquiries, rows.Err()
+	return scanInquiries(rows)
 }
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MonthStat is one calendar month of inquiries, bookings and deposits.
+type MonthStat struct {
+	Month        time.Month
+	Inquiries    int   // received in the month, spam excluded
+	Bookings     int   // of those inquiries, booked or paid a deposit
+	DepositCents int64 // deposits paid in the month, refunds excluded
+}
+
+// TripStat is one trip's inquiries for a season and what they turned into.
+type TripStat struct {
+	Slug         string
+	Inquiries    int
+	Bookings     int   // booked, or paid a deposit
+	DepositCents int64 // paid deposits on these inquiries, refunds excluded
+	LeadDays     float64
+	LeadCount    int // inquiries with a trip date, which LeadDays averages over
+}
+
+// ConversionRate returns the share of inquiries that booked, as a whole
+// percent.
+func (t TripStat) ConversionRate() int {
+	if t.Inquiries == 0 {
+		return 0
+	}
+	return t.Bookings * 100 / t.Inquiries
+}
+
+// paidByInquiry sums paid deposits per inquiry, for joining onto
+// inquiries. An inquiry with a row here counts as a booking.
+const paidByInquiry = `(
+	SELECT inquiry_id, SUM(amount_cents) AS cents FROM payments WHERE status = 'paid' GROUP BY inquiry_id
+)`
+
+// MonthlyStats returns twelve months of the given year, January first.
+// Inquiries and bookings are counted in the month the inquiry came in;
+// deposits in the month they were paid.
+func (s *Store) MonthlyStats(year int) ([]MonthStat, error) {
+	months := make([]MonthStat, 12)
+	for i := range months {
+		months[i].Month = time.Month(i + 1)
+	}
+	y := strconv.Itoa(year)
+
+	rows, err := s.db.Query(`
+		SELECT CAST(strftime('%m', i.created_at) AS INTEGER),
+			COUNT(*),
+			SUM(CASE WHEN i.status = 'booked' OR p.cents IS NOT NULL THEN 1 ELSE 0 END)
+		FROM inquiries i
+		LEFT JOIN `+paidByInquiry+` p ON p.inquiry_id = i.id
+		WHERE i.status != 'spam' AND strftime('%Y', i.created_at) = ?
+		GROUP BY 1`, y)
+	if err != nil {
+		return nil, fmt.Errorf("monthly stats %d: %w", year, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m, inquiries, bookings int
+		if err := rows.Scan(&m, &inquiries, &bookings); err != nil {
+			return nil, fmt.Errorf("scan monthly stats: %w", err)
+		}
+		if m >= 1 && m <= 12 {
+			months[m-1].Inquiries = inquiries
+			months[m-1].Bookings = bookings
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("monthly stats %d: %w", year, err)
+	}
+
+	rows, err = s.db.Query(`
+		SELECT CAST(strftime('%m', paid_at) AS INTEGER), SUM(amount_cents)
+		FROM payments
+		WHERE status = 'paid' AND strftime('%Y', paid_at) = ?
+		GROUP BY 1`, y)
+	if err != nil {
+		return nil, fmt.Errorf("monthly deposits %d: %w", year, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m int
+		var cents int64
+		if err := rows.Scan(&m, &cents); err != nil {
+			return nil, fmt.Errorf("scan monthly deposits: %w", err)
+		}
+		if m >= 1 && m <= 12 {
+			months[m-1].DepositCents = cents
+		}
+	}
+	return months, rows.Err()
+}
+
+// TripStats totals the given year's inquiries by trip, leaving out spam.
+// Lead time is the days from the inquiry to the trip's start, averaged
+// over inquiries that have a start date on or after the day they came in.
+// Rows are ordered by inquiry count.
+func (s *Store) TripStats(year int) ([]TripStat, error) {
+	rows, err := s.db.Query(`
+		SELECT i.trip_slug,
+			COUNT(*),
+			SUM(CASE WHEN i.status = 'booked' OR p.cents IS NOT NULL THEN 1 ELSE 0 END),
+			COALESCE(SUM(p.cents), 0),
+			AVG(l.days),
+			COUNT(l.days)
+		FROM inquiries i
+		LEFT JOIN `+paidByInquiry+` p ON p.inquiry_id = i.id
+		LEFT JOIN (
+			SELECT id, julianday(trip_start) - julianday(date(created_at)) AS days FROM inquiries
+			WHERE trip_start IS NOT NULL AND julianday(trip_start) >= julianday(date(created_at))
+		) l ON l.id = i.id
+		WHERE i.status != 'spam' AND strftime('%Y', i.created_at) = ?
+		GROUP BY i.trip_slug
+		ORDER BY COUNT(*) DESC, i.trip_slug`,
+		strconv.Itoa(year))
+	if err != nil {
+		return nil, fmt.Errorf("trip stats %d: %w", year, err)
+	}
+	defer rows.Close()
+
+	var stats []TripStat
+	for rows.Next() {
+		var st TripStat
+		var lead sql.NullFloat64
+		if err := rows.Scan(&st.Slug, &st.Inquiries, &st.Bookings, &st.DepositCents, &lead, &st.LeadCount); err != nil {
+			return nil, fmt.Errorf("scan trip stat: %w", err)
+		}
+		st.LeadDays = lead.Float64
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}
+
+// FirstInquiryYear returns the year of the oldest inquiry, or 0 if there
+// are none.
+func (s *Store) FirstInquiryYear() (int, error) {
+	var y sql.NullString
+	err := s.db.QueryRow(`SELECT strftime('%Y', MIN(created_at)) FROM inquiries WHERE status != 'spam'`).Scan(&y)
+	if err != nil {
+		return 0, fmt.Errorf("first inquiry year: %w", err)
+	}
+	if !y.Valid {
+		return 0, nil
+	}
+	n, _ := strconv.Atoi(y.String)
+	return n, nil
+}
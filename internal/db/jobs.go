@@ -0,0 +1,94 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// JobRun is one execution of a scheduled job.
+type JobRun struct {
+	ID         int64
+	Job        string
+	Status     string // running, ok, error, interrupted
+	Error      string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+	Duration   time.Duration
+}
+
+const jobRunColumns = `id, job, status, error, started_at, finished_at, duration_ms`
+
+func scanJobRun(row rowScanner, r *JobRun) error {
+	var finished sql.NullTime
+	var ms int64
+	if err := row.Scan(&r.ID, &r.Job, &r.Status, &r.Error, &r.StartedAt, &finished, &ms); err != nil {
+		return err
+	}
+	r.FinishedAt = nil
+	if finished.Valid {
+		r.FinishedAt = &finished.Time
+	}
+	r.Duration = time.Duration(ms) * time.Millisecond
+	return nil
+}
+
+// StartJobRun records that a job began running and returns the run ID.
+func (s *Store) StartJobRun(job string, startedAt time.Time) (int64, error) {
+	res, err := s.db.Exec(`INSERT INTO job_runs (job, started_at) VALUES (?, ?)`, job, startedAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("start job run: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// FinishJobRun records the outcome of a run. A nil runErr marks it ok.
+func (s *Store) FinishJobRun(id int64, finishedAt time.Time, duration time.Duration, runErr error) error {
+	status, msg := "ok", ""
+	if runErr != nil {
+		status, msg = "error", runErr.Error()
+	}
+	_, err := s.db.Exec(`UPDATE job_runs SET status = ?, error = ?, finished_at = ?, duration_ms = ? WHERE id = ?`,
+		status, msg, finishedAt.UTC(), duration.Milliseconds(), id)
+	if err != nil {
+		return fmt.Errorf("finish job run: %w", err)
+	}
+	return nil
+}
+
+// InterruptJobRuns marks runs left "running" by a previous process as
+// interrupted. Call it once at startup before any job runs.
+func (s *Store) InterruptJobRuns() error {
+	if _, err := s.db.Exec(`UPDATE job_runs SET status = 'interrupted' WHERE status = 'running'`); err != nil {
+		return fmt.Errorf("interrupt job runs: %w", err)
+	}
+	return nil
+}
+
+// RecentJobRuns returns the latest runs of a job, newest first.
+func (s *Store) RecentJobRuns(job string, limit int) ([]JobRun, error) {
+	rows, err := s.db.Query(`SELECT `+jobRunColumns+` FROM job_runs WHERE job = ? ORDER BY started_at DESC, id DESC LIMIT ?`, job, limit)
+	if err != nil {
+		return nil, fmt.Errorf("recent job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []JobRun
+	for rows.Next() {
+		var r JobRun
+		if err := scanJobRun(rows, &r); err != nil {
+			return nil, fmt.Errorf("scan job run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// PruneJobRuns deletes run history older than the cutoff.
+func (s *Store) PruneJobRuns(before time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM job_runs WHERE started_at < ? AND status != 'running'`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("prune job runs: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// HuntLicense is a license or tag number a hunter has submitted for a booked hunt.
+type HuntLicense struct {
+	InquiryID     int64
+	Hunter        int    // 1-based party position, matching the guest manifest
+	Type          string // license type from the trip catalog
+	LicenseNumber string
+	TagNumber     string
+	UpdatedAt     time.Time
+}
+
+// LicensesByInquiry returns every recorded license for an inquiry.
+func (s *Store) LicensesByInquiry(inquiryID int64) ([]HuntLicense, error) {
+	rows, err := s.db.Query(`
+		SELECT inquiry_id, hunter, license_type, license_number, tag_number, updated_at
+		FROM hunt_licenses WHERE inquiry_id = ? ORDER BY hunter, license_type`, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("licenses by inquiry: %w", err)
+	}
+	defer rows.Close()
+
+	var licenses []HuntLicense
+	for rows.Next() {
+		var l HuntLicense
+		if err := rows.Scan(&l.InquiryID, &l.Hunter, &l.Type, &l.LicenseNumber, &l.TagNumber, &l.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan license: %w", err)
+		}
+		licenses = append(licenses, l)
+	}
+	return licenses, rows.Err()
+}
+
+// SaveLicenses replaces the recorded licenses for an inquiry. Entries with
+// neither a license nor a tag number are dropped.
+func (s *Store) SaveLicenses(inquiryID int64, licenses []HuntLicense) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin save licenses: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM hunt_licenses WHERE inquiry_id = ?`, inquiryID); err != nil {
+		return fmt.Errorf("clear licenses: %w", err)
+	}
+	for _, l := range licenses {
+		if l.LicenseNumber == "" && l.TagNumber == "" {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO hunt_licenses (inquiry_id, hunter, license_type, license_number, tag_number)
+			VALUES (?, ?, ?, ?, ?)`,
+			inquiryID, l.Hunter, l.Type, l.LicenseNumber, l.TagNumber,
+		); err != nil {
+			return fmt.Errorf("insert license: %w", err)
+		}
+	}
+	return tx.Commit()
+}
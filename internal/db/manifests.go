@@ -0,0 +1,161 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ExperienceLevels lists the valid guest experience levels, matching the contact form.
+var ExperienceLevels = []string{"beginner", "intermediate", "experienced"}
+
+// Manifest is the tokenized link a lead client uses to fill in their party.
+type Manifest struct {
+	InquiryID   int64
+	Token       string
+	SubmittedAt *time.Time
+	CreatedAt   time.Time
+}
+
+// Guest is one person on a booked trip.
+type Guest struct {
+	ID             int64
+	InquiryID      int64
+	Position       int // 1-based order in the party; the lead client is 1
+	Name           string
+	EmergencyName  string
+	EmergencyPhone string
+	LicenseNumber  string // hunting trips only
+	Dietary        string
+	Experience     string // beginner, intermediate, experienced, or empty
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// DayManifest is one booking going out on a given day with its guests and crew.
+type DayManifest struct {
+	Booking
+	Guests []Guest
+}
+
+// GetManifest returns the manifest link for an inquiry, or nil if none was created.
+func (s *Store) GetManifest(inquiryID int64) (*Manifest, error) {
+	return s.scanManifest(s.db.QueryRow(`SELECT inquiry_id, token, submitted_at, created_at FROM manifests WHERE inquiry_id = ?`, inquiryID))
+}
+
+// GetManifestByToken returns the manifest behind a public link.
+func (s *Store) GetManifestByToken(token string) (*Manifest, error) {
+	return s.scanManifest(s.db.QueryRow(`SELECT inquiry_id, token, submitted_at, created_at FROM manifests WHERE token = ?`, token))
+}
+
+func (s *Store) scanManifest(row *sql.Row) (*Manifest, error) {
+	m := &Manifest{}
+	var submittedAt sql.NullTime
+	err := row.Scan(&m.InquiryID, &m.Token, &submittedAt, &m.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get manifest: %w", err)
+	}
+	if submittedAt.Valid {
+		m.SubmittedAt = &submittedAt.Time
+	}
+	return m, nil
+}
+
+// CreateManifest stores a manifest link for an inquiry. An inquiry has at most
+// one; creating it again keeps the existing token.
+func (s *Store) CreateManifest(inquiryID int64, token string) error {
+	_, err := s.db.Exec(`INSERT INTO manifests (inquiry_id, token) VALUES (?, ?)
+		ON CONFLICT(inquiry_id) DO NOTHING`, inquiryID, token)
+	if err != nil {
+		return fmt.Errorf("create manifest: %w", err)
+	}
+	return nil
+}
+
+const guestColumns = `id, inquiry_id, position, name, emergency_name, emergency_phone, license_number, dietary, experience, created_at, updated_at`
+
+func scanGuest(row rowScanner, g *Guest) error {
+	return row.Scan(&g.ID, &g.InquiryID, &g.Position, &g.Name, &g.EmergencyName, &g.EmergencyPhone,
+		&g.LicenseNumber, &g.Dietary, &g.Experience, &g.CreatedAt, &g.UpdatedAt)
+}
+
+// GuestsByInquiry returns the party for an inquiry in manifest order.
+func (s *Store) GuestsByInquiry(inquiryID int64) ([]Guest, error) {
+	rows, err := s.db.Query(`SELECT `+guestColumns+` FROM guests WHERE inquiry_id = ? ORDER BY position, id`, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("guests by inquiry: %w", err)
+	}
+	defer rows.Close()
+
+	var guests []Guest
+	for rows.Next() {
+		var g Guest
+		if err := scanGuest(rows, &g); err != nil {
+			return nil, fmt.Errorf("scan guest: %w", err)
+		}
+		guests = append(guests, g)
+	}
+	return guests, rows.Err()
+}
+
+// SaveGuests replaces an inquiry's party with the given guests and marks the
+// manifest submitted. Positions are assigned in slice order.
+func (s *Store) SaveGuests(inquiryID int64, guests []Guest) error {
+	for _, g := range guests {
+		if g.Name == "" {
+			return fmt.Errorf("guest name is required")
+		}
+		valid := g.Experience == ""
+		for _, lvl := range ExperienceLevels {
+			if g.Experience == lvl {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid experience level: %s", g.Experience)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin save guests: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM guests WHERE inquiry_id = ?`, inquiryID); err != nil {
+		return fmt.Errorf("clear guests: %w", err)
+	}
+	for i, g := range guests {
+		if _, err := tx.Exec(`
+			INSERT INTO guests (inquiry_id, position, name, emergency_name, emergency_phone, license_number, dietary, experience)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			inquiryID, i+1, g.Name, g.EmergencyName, g.EmergencyPhone, g.LicenseNumber, g.Dietary, g.Experience,
+		); err != nil {
+			return fmt.Errorf("insert guest: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE manifests SET submitted_at = datetime('now') WHERE inquiry_id = ?`, inquiryID); err != nil {
+		return fmt.Errorf("mark manifest submitted: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ManifestForDay returns every booking out on the given day with its guests.
+func (s *Store) ManifestForDay(day time.Time) ([]DayManifest, error) {
+	bookings, err := s.BookingsBetween(day, day)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]DayManifest, 0, len(bookings))
+	for _, b := range bookings {
+		guests, err := s.GuestsByInquiry(b.Inquiry.ID)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, DayManifest{Booking: b, Guests: guests})
+	}
+	return out, nil
+}
@@ -29,6 +29,16 @@ func (s *Store) migrate() error {
 		file    string
 	}{
 		{1, "migrations/001_initial.sql"},
+		{2, "migrations/002_resources.sql"},
+		{3, "migrations/003_waivers.sql"},
+		{4, "migrations/004_manifests.sql"},
+		{5, "migrations/005_licenses.sql"},
+		{6, "migrations/006_job_runs.sql"},
+		{7, "migrations/007_fishing_reports.sql"},
+		{8, "migrations/008_gallery.sql"},
+		{9, "migrations/009_spam.sql"},
+		{10, "migrations/010_attribution.sql"},
+		{11, "migrations/011_analytics.sql"},
 	}
 
 	for _, m := range needed {
@@ -0,0 +1,30 @@
+-- 002_resources.sql
+-- Adds guides and equipment as schedulable resources, plus confirmed trip
+-- dates on inquiries so bookings can be placed on the calendar.
+
+ALTER TABLE inquiries ADD COLUMN trip_start DATE;
+ALTER TABLE inquiries ADD COLUMN trip_end DATE;
+
+CREATE INDEX IF NOT EXISTS idx_inquiries_trip_dates ON inquiries(trip_start, trip_end);
+
+CREATE TABLE IF NOT EXISTS resources (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK(kind IN ('guide','boat','raft','pack-stock')),
+    trip_slugs TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    active INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS resource_assignments (
+    inquiry_id INTEGER NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    resource_id INTEGER NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (inquiry_id, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignments_resource ON resource_assignments(resource_id);
+
+INSERT INTO schema_version (version) VALUES (2);
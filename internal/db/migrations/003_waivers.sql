@@ -0,0 +1,68 @@
+-- 003_waivers.sql
+-- Versioned liability release templates per trip category, and one signing
+-- record per party member on a booked inquiry.
+
+CREATE TABLE IF NOT EXISTS waiver_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    category TEXT NOT NULL CHECK(category IN ('Fishing','Hunting','Packages')),
+    version INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    UNIQUE (category, version)
+);
+
+CREATE TABLE IF NOT EXISTS waivers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    inquiry_id INTEGER NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    template_id INTEGER NOT NULL REFERENCES waiver_templates(id),
+    token TEXT NOT NULL UNIQUE,
+    party_index INTEGER NOT NULL,
+    signer_name TEXT NOT NULL DEFAULT '',
+    signer_ip TEXT NOT NULL DEFAULT '',
+    document_hash TEXT NOT NULL DEFAULT '',
+    signed_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_waivers_inquiry ON waivers(inquiry_id);
+
+INSERT INTO waiver_templates (category, version, title, body) VALUES
+('Fishing', 1, 'Release of Liability — Guided Fishing',
+'I am voluntarily taking part in a guided fishing trip with MT Hunt & Fish Outfitters, including travel by jet boat, drift boat, or on foot along rivers and lakes in Montana.
+
+I understand that fishing from boats and wading in moving water carries real risks, including slipping, falling, capsizing, cold water immersion, drowning, hook injuries, changing weather, and wildlife encounters. Some of these risks cannot be eliminated even with careful guiding.
+
+I agree to follow the guide''s instructions, to wear a personal flotation device when asked, and to tell the guide about any medical condition that could affect my safety on the water.
+
+I hold a valid Montana fishing license for the dates of the trip, or I will buy one before the trip starts.
+
+In exchange for being allowed to take part, I release MT Hunt & Fish Outfitters, its owner, guides, and employees from liability for injury, loss, or damage arising from the inherent risks of the activity, to the fullest extent allowed by Montana law (MCA 27-1-753).
+
+I have read this release, I understand it, and I am signing it freely.'),
+('Hunting', 1, 'Release of Liability — Guided Hunting',
+'I am voluntarily taking part in a guided hunt with MT Hunt & Fish Outfitters in the mountains, timber, and prairie of Montana, which may include hiking steep terrain, riding in vehicles or on stock, and handling firearms or archery equipment.
+
+I understand that hunting carries real risks, including falls, firearm and broadhead accidents, exposure to cold and altitude, wildlife encounters, stock-related injuries, and remote locations far from medical help. Some of these risks cannot be eliminated even with careful guiding.
+
+I agree to follow the guide''s instructions and all firearm safety rules, to wear hunter orange where required, and to tell the guide about any medical condition that could affect my safety in the field.
+
+I am responsible for holding the correct Montana hunting licenses, permits, and tags for the species and dates of the hunt.
+
+In exchange for being allowed to take part, I release MT Hunt & Fish Outfitters, its owner, guides, and employees from liability for injury, loss, or damage arising from the inherent risks of the activity, to the fullest extent allowed by Montana law (MCA 27-1-753).
+
+I have read this release, I understand it, and I am signing it freely.'),
+('Packages', 1, 'Release of Liability — Multi-Day Package',
+'I am voluntarily taking part in a multi-day package with MT Hunt & Fish Outfitters that combines guided fishing and hunting in Montana, along with lodging and transport arranged by the outfitter.
+
+I understand that the fishing days carry the risks of boats and moving water, and the hunting days carry the risks of firearms, steep terrain, stock, weather, and wildlife. Some of these risks cannot be eliminated even with careful guiding.
+
+I agree to follow the guide''s instructions, to wear a personal flotation device and hunter orange when asked, and to tell the guide about any medical condition that could affect my safety.
+
+I am responsible for holding the Montana fishing and hunting licenses, permits, and tags needed for the activities I take part in.
+
+In exchange for being allowed to take part, I release MT Hunt & Fish Outfitters, its owner, guides, and employees from liability for injury, loss, or damage arising from the inherent risks of the activities, to the fullest extent allowed by Montana law (MCA 27-1-753).
+
+I have read this release, I understand it, and I am signing it freely.');
+
+INSERT INTO schema_version (version) VALUES (3);
@@ -0,0 +1,28 @@
+-- 004_manifests.sql
+-- Adds per-guest manifests for booked parties. The lead client fills in
+-- everyone's details through a tokenized link.
+
+CREATE TABLE IF NOT EXISTS manifests (
+    inquiry_id INTEGER PRIMARY KEY REFERENCES inquiries(id) ON DELETE CASCADE,
+    token TEXT NOT NULL UNIQUE,
+    submitted_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS guests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    inquiry_id INTEGER NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    emergency_name TEXT NOT NULL DEFAULT '',
+    emergency_phone TEXT NOT NULL DEFAULT '',
+    license_number TEXT NOT NULL DEFAULT '',
+    dietary TEXT NOT NULL DEFAULT '',
+    experience TEXT NOT NULL DEFAULT '' CHECK(experience IN ('','beginner','intermediate','experienced')),
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_guests_inquiry ON guests(inquiry_id, position);
+
+INSERT INTO schema_version (version) VALUES (4);
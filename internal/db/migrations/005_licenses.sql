@@ -0,0 +1,22 @@
+-- 005_licenses.sql
+-- Tracks hunting license and tag numbers per hunter on booked hunts, and
+-- records which reminder emails have gone out so each is sent once.
+
+CREATE TABLE IF NOT EXISTS hunt_licenses (
+    inquiry_id INTEGER NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    hunter INTEGER NOT NULL,
+    license_type TEXT NOT NULL,
+    license_number TEXT NOT NULL DEFAULT '',
+    tag_number TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (inquiry_id, hunter, license_type)
+);
+
+CREATE TABLE IF NOT EXISTS notifications_sent (
+    inquiry_id INTEGER NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    sent_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (inquiry_id, kind)
+);
+
+INSERT INTO schema_version (version) VALUES (5);
@@ -0,0 +1,16 @@
+-- 006_job_runs.sql
+-- Run history for the in-process job scheduler.
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK(status IN ('running','ok','error','interrupted')),
+    error TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    duration_ms INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs(job, started_at DESC);
+
+INSERT INTO schema_version (version) VALUES (6);
@@ -0,0 +1,22 @@
+-- 007_fishing_reports.sql
+-- Fishing reports written in the admin, with structured river conditions.
+
+CREATE TABLE IF NOT EXISTS fishing_reports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    slug TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    river TEXT NOT NULL,
+    flow_cfs INTEGER,
+    water_temp_f INTEGER,
+    hatches TEXT NOT NULL DEFAULT '',
+    flies TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'draft' CHECK(status IN ('draft','published')),
+    published_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_fishing_reports_published ON fishing_reports(status, published_at DESC);
+
+INSERT INTO schema_version (version) VALUES (7);
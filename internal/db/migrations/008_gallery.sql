@@ -0,0 +1,41 @@
+-- 008_gallery.sql
+-- Gallery photos managed in the admin. Variants live on disk under
+-- static/img/gallery/<name>-{400w,800w,1600w,thumb}.jpg (.webp for the
+-- photos that shipped with the site, and uploads with transparency); this
+-- table holds their metadata and display order. Seeded with the photos
+-- that shipped with the site.
+
+CREATE TABLE IF NOT EXISTS gallery_photos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    alt TEXT NOT NULL CHECK(alt <> ''),
+    caption TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL CHECK(category IN ('fishing','hunting','scenery','camp')),
+    position INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_gallery_photos_position ON gallery_photos(position);
+
+INSERT INTO gallery_photos (name, alt, category, position) VALUES
+    ('gallery-01', 'Jet boat on the Missouri at sunrise', 'fishing', 1),
+    ('gallery-02', 'Drift boat below Holter Dam with mountains behind', 'fishing', 2),
+    ('gallery-03', 'Angler landing a brown trout on the Missouri', 'fishing', 3),
+    ('gallery-04', 'Canyon Ferry Lake at golden hour with calm water', 'fishing', 4),
+    ('gallery-05', 'Wade fishing in a side channel of the Missouri', 'fishing', 5),
+    ('gallery-06', 'Elk on a ridge in the Elkhorn Mountains at dawn', 'hunting', 6),
+    ('gallery-07', 'Mule deer buck in the Big Belt foothills', 'hunting', 7),
+    ('gallery-08', 'Hunter glassing a valley from a rocky overlook', 'hunting', 8),
+    ('gallery-09', 'Antelope on open prairie south of Helena', 'hunting', 9),
+    ('gallery-10', 'Missouri River canyon in fall color', 'scenery', 10),
+    ('gallery-11', 'Snow-capped peaks above the Gates of the Mountains', 'scenery', 11),
+    ('gallery-12', 'Sunrise over the Helena Valley looking west', 'scenery', 12),
+    ('gallery-13', 'Elkhorn Mountains with wildflower meadow in foreground', 'scenery', 13),
+    ('gallery-14', 'Storm clouds building over Canyon Ferry Lake', 'scenery', 14),
+    ('gallery-15', 'Campfire on the riverbank after a full day on the water', 'camp', 15),
+    ('gallery-16', 'Camp kitchen setup with the Missouri in the background', 'camp', 16),
+    ('gallery-17', 'Gear laid out before a morning hunt', 'camp', 17),
+    ('gallery-18', 'Tailgate lunch with a view of the valley', 'camp', 18);
+
+INSERT INTO schema_version (version) VALUES (8);
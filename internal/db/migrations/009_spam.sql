@@ -0,0 +1,46 @@
+-- 009_spam.sql
+-- Quarantine for suspected spam. SQLite can't alter a CHECK constraint,
+-- so inquiries is rebuilt to allow the 'spam' status, with a column for
+-- why the scorer flagged it. Foreign keys are off during the swap so the
+-- tables pointing at inquiries keep their rows.
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN;
+
+CREATE TABLE inquiries_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    phone TEXT NOT NULL DEFAULT '',
+    trip_slug TEXT NOT NULL DEFAULT '',
+    trip_name TEXT NOT NULL DEFAULT '',
+    dates TEXT NOT NULL DEFAULT '',
+    party_size TEXT NOT NULL DEFAULT '',
+    experience TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'new' CHECK(status IN ('new','contacted','booked','archived','spam')),
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    trip_start DATE,
+    trip_end DATE,
+    spam_reasons TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO inquiries_new (id, name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, notes, created_at, updated_at, trip_start, trip_end)
+SELECT id, name, email, phone, trip_slug, trip_name, dates, party_size, experience, message, status, notes, created_at, updated_at, trip_start, trip_end
+FROM inquiries;
+
+DROP TABLE inquiries;
+ALTER TABLE inquiries_new RENAME TO inquiries;
+
+CREATE INDEX IF NOT EXISTS idx_inquiries_status ON inquiries(status);
+CREATE INDEX IF NOT EXISTS idx_inquiries_created ON inquiries(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_inquiries_trip_dates ON inquiries(trip_start, trip_end);
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
+
+INSERT INTO schema_version (version) VALUES (9);
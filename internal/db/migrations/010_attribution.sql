@@ -0,0 +1,16 @@
+-- 010_attribution.sql
+-- Where each inquiry came from: the first landing page, referrer and UTM
+-- parameters a visitor arrived with, plus the source and medium derived
+-- from them.
+
+ALTER TABLE inquiries ADD COLUMN source TEXT NOT NULL DEFAULT '';
+ALTER TABLE inquiries ADD COLUMN medium TEXT NOT NULL DEFAULT '';
+ALTER TABLE inquiries ADD COLUMN campaign TEXT NOT NULL DEFAULT '';
+ALTER TABLE inquiries ADD COLUMN term TEXT NOT NULL DEFAULT '';
+ALTER TABLE inquiries ADD COLUMN content TEXT NOT NULL DEFAULT '';
+ALTER TABLE inquiries ADD COLUMN referrer TEXT NOT NULL DEFAULT '';
+ALTER TABLE inquiries ADD COLUMN landing_page TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_inquiries_source ON inquiries(source, medium, campaign);
+
+INSERT INTO schema_version (version) VALUES (10);
@@ -0,0 +1,19 @@
+-- 011_analytics.sql
+-- Daily page view rollups from the built-in analytics. Each row counts the
+-- views and unique visitors one day for one key of a kind: the whole site
+-- (key ''), a page path, a referring host, a device class, or a trip whose
+-- gear page was viewed or whose inquiry form was opened. No per-visitor
+-- data is stored.
+
+CREATE TABLE IF NOT EXISTS analytics_daily (
+    day DATE NOT NULL,
+    kind TEXT NOT NULL CHECK(kind IN ('site','page','referrer','device','trip_view','trip_contact')),
+    key TEXT NOT NULL DEFAULT '',
+    views INTEGER NOT NULL DEFAULT 0,
+    visitors INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, kind, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_kind_day ON analytics_daily(kind, day);
+
+INSERT INTO schema_version (version) VALUES (11);
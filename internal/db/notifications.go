@@ -0,0 +1,46 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Notified reports whether a notification of the given kind was already sent for an inquiry.
+func (s *Store) Notified(inquiryID int64, kind string) (bool, error) {
+	var one int
+	err := s.db.QueryRow(`SELECT 1 FROM notifications_sent WHERE inquiry_id = ? AND kind = ?`, inquiryID, kind).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check notification: %w", err)
+	}
+	return true, nil
+}
+
+// RecordNotification marks a notification as sent so it isn't repeated.
+func (s *Store) RecordNotification(inquiryID int64, kind string) error {
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO notifications_sent (inquiry_id, kind) VALUES (?, ?)`, inquiryID, kind)
+	if err != nil {
+		return fmt.Errorf("record notification: %w", err)
+	}
+	return nil
+}
+
+// LastNotified returns when the most recent notification with the given kind
+// prefix went out for an inquiry, or nil if none has.
+func (s *Store) LastNotified(inquiryID int64, kindPrefix string) (*time.Time, error) {
+	var sentAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT sent_at FROM notifications_sent
+		WHERE inquiry_id = ? AND kind LIKE ? || '%'
+		ORDER BY sent_at DESC LIMIT 1`, inquiryID, kindPrefix).Scan(&sentAt)
+	if err == sql.ErrNoRows || (err == nil && !sentAt.Valid) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("last notification: %w", err)
+	}
+	return &sentAt.Time, nil
+}
@@ -0,0 +1,306 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResourceKinds lists the valid resource kinds in display order.
+var ResourceKinds = []string{"guide", "boat", "raft", "pack-stock"}
+
+// Resource is a guide or piece of equipment that can be assigned to a booking.
+type Resource struct {
+	ID        int64
+	Name      string
+	Kind      string   // guide, boat, raft, pack-stock
+	TripSlugs []string // trips this resource serves; used to derive public availability
+	Notes     string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Serves reports whether the resource is used for the given trip slug.
+func (r Resource) Serves(slug string) bool {
+	for _, s := range r.TripSlugs {
+		if s == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// Booking is a booked inquiry with confirmed dates and its assigned resources.
+type Booking struct {
+	Inquiry   Inquiry
+	Resources []Resource
+}
+
+// Overlaps reports whether the booking's dates intersect [from, to] (inclusive).
+func (b Booking) Overlaps(from, to time.Time) bool {
+	if b.Inquiry.TripStart == nil || b.Inquiry.TripEnd == nil {
+		return false
+	}
+	return !b.Inquiry.TripStart.After(to) && !b.Inquiry.TripEnd.Before(from)
+}
+
+// Conflict describes a resource double-booked across two overlapping bookings.
+type Conflict struct {
+	Resource Resource
+	Other    Inquiry // the other booking holding the resource
+}
+
+const resourceColumns = `id, name, kind, trip_slugs, notes, active, created_at, updated_at`
+
+func scanResource(row rowScanner, r *Resource) error {
+	var slugs string
+	var active int
+	if err := row.Scan(&r.ID, &r.Name, &r.Kind, &slugs, &r.Notes, &active, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return err
+	}
+	r.TripSlugs = splitSlugs(slugs)
+	r.Active = active == 1
+	return nil
+}
+
+func splitSlugs(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func validResourceKind(kind string) bool {
+	for _, k := range ResourceKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ListResources returns resources ordered by kind and name. When activeOnly is
+// true, retired resources are left out.
+func (s *Store) ListResources(activeOnly bool) ([]Resource, error) {
+	query := `SELECT ` + resourceColumns + ` FROM resources`
+	if activeOnly {
+		query += ` WHERE active = 1`
+	}
+	query += ` ORDER BY kind, name`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("list resources: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []Resource
+	for rows.Next() {
+		var r Resource
+		if err := scanResource(rows, &r); err != nil {
+			return nil, fmt.Errorf("scan resource: %w", err)
+		}
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
+}
+
+// GetResource returns a single resource by ID.
+func (s *Store) GetResource(id int64) (*Resource, error) {
+	r := &Resource{}
+	err := scanResource(s.db.QueryRow(`SELECT `+resourceColumns+` FROM resources WHERE id = ?`, id), r)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get resource %d: %w", id, err)
+	}
+	return r, nil
+}
+
+// SaveResource inserts a new resource (ID 0) or updates an existing one.
+func (s *Store) SaveResource(r *Resource) (int64, error) {
+	if strings.TrimSpace(r.Name) == "" {
+		return 0, fmt.Errorf("resource name is required")
+	}
+	if !validResourceKind(r.Kind) {
+		return 0, fmt.Errorf("invalid resource kind: %s", r.Kind)
+	}
+	active := 0
+	if r.Active {
+		active = 1
+	}
+	slugs := strings.Join(r.TripSlugs, ",")
+
+	if r.ID == 0 {
+		res, err := s.db.Exec(`
+			INSERT INTO resources (name, kind, trip_slugs, notes, active)
+			VALUES (?, ?, ?, ?, ?)`,
+			r.Name, r.Kind, slugs, r.Notes, active,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("create resource: %w", err)
+		}
+		return res.LastInsertId()
+	}
+
+	_, err := s.db.Exec(`
+		UPDATE resources SET name = ?, kind = ?, trip_slugs = ?, notes = ?, active = ?, updated_at = datetime('now')
+		WHERE id = ?`,
+		r.Name, r.Kind, slugs, r.Notes, active, r.ID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("update resource %d: %w", r.ID, err)
+	}
+	return r.ID, nil
+}
+
+// SetInquiryResources replaces the resources assigned to an inquiry.
+func (s *Store) SetInquiryResources(inquiryID int64, resourceIDs []int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("set inquiry resources: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM resource_assignments WHERE inquiry_id = ?`, inquiryID); err != nil {
+		return fmt.Errorf("clear assignments: %w", err)
+	}
+	for _, rid := range resourceIDs {
+		if _, err := tx.Exec(`INSERT INTO resource_assignments (inquiry_id, resource_id) VALUES (?, ?)`, inquiryID, rid); err != nil {
+			return fmt.Errorf("assign resource %d: %w", rid, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// InquiryResources returns the resources assigned to an inquiry.
+func (s *Store) InquiryResources(inquiryID int64) ([]Resource, error) {
+	rows, err := s.db.Query(`
+		SELECT r.id, r.name, r.kind, r.trip_slugs, r.notes, r.active, r.created_at, r.updated_at
+		FROM resources r
+		JOIN resource_assignments a ON a.resource_id = r.id
+		WHERE a.inquiry_id = ?
+		ORDER BY r.kind, r.name`, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("inquiry resources: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []Resource
+	for rows.Next() {
+		var r Resource
+		if err := scanResource(rows, &r); err != nil {
+			return nil, fmt.Errorf("scan resource: %w", err)
+		}
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
+}
+
+// BookingsBetween returns booked inquiries whose confirmed dates overlap
+// [from, to] (inclusive), each with its assigned resources.
+func (s *Store) BookingsBetween(from, to time.Time) ([]Booking, error) {
+	rows, err := s.db.Query(`SELECT `+inquiryColumns+` FROM inquiries
+		WHERE status = 'booked' AND trip_start IS NOT NULL AND trip_end IS NOT NULL
+		  AND trip_start <= ? AND trip_end >= ?
+		ORDER BY trip_start, id`, to.Format(DateLayout), from.Format(DateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("bookings between: %w", err)
+	}
+	inquiries, err := scanInquiries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings := make([]Booking, 0, len(inquiries))
+	for _, inq := range inquiries {
+		resources, err := s.InquiryResources(inq.ID)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, Booking{Inquiry: inq, Resources: resources})
+	}
+	return bookings, nil
+}
+
+// InquiryConflicts returns every resource assigned to the inquiry that is also
+// held by another booked inquiry on overlapping dates.
+func (s *Store) InquiryConflicts(inquiryID int64) ([]Conflict, error) {
+	rows, err := s.db.Query(`
+		SELECT r.id, r.name, r.kind, r.trip_slugs, r.notes, r.active, r.created_at, r.updated_at,
+		       o.id, o.name, o.trip_name, o.trip_start, o.trip_end
+		FROM resource_assignments a
+		JOIN inquiries i ON i.id = a.inquiry_id
+		JOIN resource_assignments b ON b.resource_id = a.resource_id AND b.inquiry_id != a.inquiry_id
+		JOIN inquiries o ON o.id = b.inquiry_id
+		JOIN resources r ON r.id = a.resource_id
+		WHERE a.inquiry_id = ?
+		  AND o.status = 'booked'
+		  AND i.trip_start IS NOT NULL AND i.trip_end IS NOT NULL
+		  AND o.trip_start IS NOT NULL AND o.trip_end IS NOT NULL
+		  AND o.trip_start <= i.trip_end AND o.trip_end >= i.trip_start
+		ORDER BY o.trip_start, r.name`, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("inquiry conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []Conflict
+	for rows.Next() {
+		var c Conflict
+		var slugs string
+		var active int
+		var start, end sql.NullTime
+		if err := rows.Scan(&c.Resource.ID, &c.Resource.Name, &c.Resource.Kind, &slugs, &c.Resource.Notes, &active, &c.Resource.CreatedAt, &c.Resource.UpdatedAt,
+			&c.Other.ID, &c.Other.Name, &c.Other.TripName, &start, &end); err != nil {
+			return nil, fmt.Errorf("scan conflict: %w", err)
+		}
+		c.Resource.TripSlugs = splitSlugs(slugs)
+		c.Resource.Active = active == 1
+		if start.Valid {
+			c.Other.TripStart = &start.Time
+		}
+		if end.Valid {
+			c.Other.TripEnd = &end.Time
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, rows.Err()
+}
+
+// ConflictingInquiries returns the IDs of bookings in the list that share a
+// resource with another booking on overlapping dates.
+func ConflictingInquiries(bookings []Booking) map[int64]bool {
+	out := make(map[int64]bool)
+	for i := range bookings {
+		a := bookings[i]
+		for j := i + 1; j < len(bookings); j++ {
+			b := bookings[j]
+			if !a.Overlaps(*b.Inquiry.TripStart, *b.Inquiry.TripEnd) {
+				continue
+			}
+			if sharesResource(a.Resources, b.Resources) {
+				out[a.Inquiry.ID] = true
+				out[b.Inquiry.ID] = true
+			}
+		}
+	}
+	return out
+}
+
+func sharesResource(a, b []Resource) bool {
+	for _, ra := range a {
+		for _, rb := range b {
+			if ra.ID == rb.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
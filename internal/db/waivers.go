@@ -0,0 +1,241 @@
+package db
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WaiverCategories lists the trip categories that carry their own waiver text.
+var WaiverCategories = []string{"Fishing", "Hunting", "Packages"}
+
+// WaiverTemplate is one immutable version of the release for a trip category.
+type WaiverTemplate struct {
+	ID        int64
+	Category  string // Fishing, Hunting, Packages
+	Version   int
+	Title     string
+	Body      string
+	CreatedAt time.Time
+}
+
+// Paragraphs splits the body on blank lines for display.
+func (t *WaiverTemplate) Paragraphs() []string {
+	var out []string
+	for _, p := range strings.Split(strings.ReplaceAll(t.Body, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Waiver is a signing slot for one member of a booked party.
+type Waiver struct {
+	ID           int64
+	InquiryID    int64
+	TemplateID   int64
+	Token        string
+	PartyIndex   int // 1-based position in the party
+	SignerName   string
+	SignerIP     string
+	DocumentHash string
+	SignedAt     *time.Time
+	CreatedAt    time.Time
+}
+
+// Signed reports whether the waiver has been signed.
+func (w *Waiver) Signed() bool {
+	return w.SignedAt != nil
+}
+
+// WaiverDocumentHash returns the SHA-256 of the exact text a signer agreed to:
+// the template version, the signer's typed name, the inquiry, and the signing
+// time. Recomputing it later proves the stored record hasn't been altered.
+func WaiverDocumentHash(t *WaiverTemplate, inquiryID int64, signerName string, signedAt time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nCategory: %s, version %d\n\n%s\n\n", t.Title, t.Category, t.Version, strings.ReplaceAll(t.Body, "\r\n", "\n"))
+	fmt.Fprintf(&b, "Inquiry: #%d\nSigned by: %s\nSigned at: %s\n", inquiryID, signerName, signedAt.UTC().Format(time.RFC3339))
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+const waiverTemplateColumns = `id, category, version, title, body, created_at`
+
+func scanWaiverTemplate(row rowScanner, t *WaiverTemplate) error {
+	return row.Scan(&t.ID, &t.Category, &t.Version, &t.Title, &t.Body, &t.CreatedAt)
+}
+
+// LatestWaiverTemplate returns the newest template version for a category.
+func (s *Store) LatestWaiverTemplate(category string) (*WaiverTemplate, error) {
+	t := &WaiverTemplate{}
+	err := scanWaiverTemplate(s.db.QueryRow(`SELECT `+waiverTemplateColumns+` FROM waiver_templates
+		WHERE category = ? ORDER BY version DESC LIMIT 1`, category), t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("latest waiver template %s: %w", category, err)
+	}
+	return t, nil
+}
+
+// GetWaiverTemplate returns a template version by ID.
+func (s *Store) GetWaiverTemplate(id int64) (*WaiverTemplate, error) {
+	t := &WaiverTemplate{}
+	err := scanWaiverTemplate(s.db.QueryRow(`SELECT `+waiverTemplateColumns+` FROM waiver_templates WHERE id = ?`, id), t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get waiver template %d: %w", id, err)
+	}
+	return t, nil
+}
+
+// ListWaiverTemplates returns every template version, newest first within each category.
+func (s *Store) ListWaiverTemplates() ([]WaiverTemplate, error) {
+	rows, err := s.db.Query(`SELECT ` + waiverTemplateColumns + ` FROM waiver_templates ORDER BY category, version DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list waiver templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []WaiverTemplate
+	for rows.Next() {
+		var t WaiverTemplate
+		if err := scanWaiverTemplate(rows, &t); err != nil {
+			return nil, fmt.Errorf("scan waiver template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// PublishWaiverTemplate stores a new version of a category's waiver. Earlier
+// versions are kept so signed waivers always point at the text they saw.
+func (s *Store) PublishWaiverTemplate(category, title, body string) (*WaiverTemplate, error) {
+	valid := false
+	for _, c := range WaiverCategories {
+		if c == category {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid waiver category: %s", category)
+	}
+	if strings.TrimSpace(title) == "" || strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("waiver title and body are required")
+	}
+
+	res, err := s.db.Exec(`
+		INSERT INTO waiver_templates (category, version, title, body)
+		VALUES (?, (SELECT COALESCE(MAX(version), 0) + 1 FROM waiver_templates WHERE category = ?), ?, ?)`,
+		category, category, title, body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("publish waiver template: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return s.GetWaiverTemplate(id)
+}
+
+const waiverColumns = `id, inquiry_id, template_id, token, party_index, signer_name, signer_ip, document_hash, signed_at, created_at`
+
+func scanWaiver(row rowScanner, w *Waiver) error {
+	var signedAt sql.NullTime
+	if err := row.Scan(&w.ID, &w.InquiryID, &w.TemplateID, &w.Token, &w.PartyIndex, &w.SignerName, &w.SignerIP, &w.DocumentHash, &signedAt, &w.CreatedAt); err != nil {
+		return err
+	}
+	w.SignedAt = nil
+	if signedAt.Valid {
+		w.SignedAt = &signedAt.Time
+	}
+	return nil
+}
+
+// CreateWaiver adds a signing slot for one party member.
+func (s *Store) CreateWaiver(w *Waiver) (int64, error) {
+	res, err := s.db.Exec(`
+		INSERT INTO waivers (inquiry_id, template_id, token, party_index)
+		VALUES (?, ?, ?, ?)`,
+		w.InquiryID, w.TemplateID, w.Token, w.PartyIndex,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("create waiver: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// WaiversByInquiry returns the signing slots for an inquiry in party order.
+func (s *Store) WaiversByInquiry(inquiryID int64) ([]Waiver, error) {
+	rows, err := s.db.Query(`SELECT `+waiverColumns+` FROM waivers WHERE inquiry_id = ? ORDER BY party_index, id`, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("waivers by inquiry: %w", err)
+	}
+	defer rows.Close()
+
+	var waivers []Waiver
+	for rows.Next() {
+		var w Waiver
+		if err := scanWaiver(rows, &w); err != nil {
+			return nil, fmt.Errorf("scan waiver: %w", err)
+		}
+		waivers = append(waivers, w)
+	}
+	return waivers, rows.Err()
+}
+
+// GetWaiver returns a waiver by ID.
+func (s *Store) GetWaiver(id int64) (*Waiver, error) {
+	w := &Waiver{}
+	err := scanWaiver(s.db.QueryRow(`SELECT `+waiverColumns+` FROM waivers WHERE id = ?`, id), w)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get waiver %d: %w", id, err)
+	}
+	return w, nil
+}
+
+// GetWaiverByToken returns the waiver behind a signing link.
+func (s *Store) GetWaiverByToken(token string) (*Waiver, error) {
+	w := &Waiver{}
+	err := scanWaiver(s.db.QueryRow(`SELECT `+waiverColumns+` FROM waivers WHERE token = ?`, token), w)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get waiver by token: %w", err)
+	}
+	return w, nil
+}
+
+// ErrWaiverSigned is returned by SignWaiver for a waiver that already has a
+// signature.
+var ErrWaiverSigned = errors.New("waiver already signed")
+
+// SignWaiver records a signature. It fails if the waiver was already signed,
+// so a signing link can only be used once.
+func (s *Store) SignWaiver(id int64, signerName, signerIP, documentHash string, signedAt time.Time) error {
+	res, err := s.db.Exec(`
+		UPDATE waivers SET signer_name = ?, signer_ip = ?, document_hash = ?, signed_at = ?
+		WHERE id = ? AND signed_at IS NULL`,
+		signerName, signerIP, documentHash, signedAt.UTC().Format(time.RFC3339), id,
+	)
+	if err != nil {
+		return fmt.Errorf("sign waiver: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("waiver %d: %w", id, ErrWaiverSigned)
+	}
+	return nil
+}
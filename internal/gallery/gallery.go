@@ -0,0 +1,131 @@
+// Package gallery stores uploaded gallery photos on disk: the original, the
+// 400w/800w/1600w variants and the 400×300 thumbnail, named the way
+// scripts/optimize-images.sh names them so /img/ serves uploaded and bundled
+// photos alike. Variants are JPEG, or WebP for photos with transparency.
+package gallery
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/firefly/packstring/internal/imaging"
+	"github.com/firefly/packstring/internal/resizer"
+)
+
+// Widths are the responsive variants generated for every photo.
+var Widths = []int{400, 800, 1600}
+
+// Thumbnail size, a center crop.
+const (
+	ThumbWidth  = 400
+	ThumbHeight = 300
+)
+
+// URLPrefix is where the site serves the variants.
+const URLPrefix = "/static/img/gallery"
+
+// Src returns the base URL of a photo's variants, without the size suffix.
+func Src(name string) string {
+	return URLPrefix + "/" + name
+}
+
+// Thumb returns the URL of a photo's thumbnail.
+func Thumb(name string) string {
+	u, _ := resizer.URL(Src(name), "thumb")
+	return u
+}
+
+// Library is a directory of processed photos.
+type Library struct {
+	Dir       string // variants, served at URLPrefix
+	SourceDir string // originals, kept for re-processing
+}
+
+// NewLibrary returns the library under root/static/img, where root is the
+// directory the site serves writable files from.
+func NewLibrary(root string) *Library {
+	return &Library{
+		Dir:       filepath.Join(root, "static", "img", "gallery"),
+		SourceDir: filepath.Join(root, "static", "img", "source", "gallery"),
+	}
+}
+
+// Save keeps the original upload and writes every variant of img under
+// name. ext is the original's extension, e.g. ".jpg". A variant that can't
+// be brought under resizer.MaxBytes fails the save, and on failure nothing
+// is left behind.
+func (l *Library) Save(name string, original []byte, ext string, img image.Image) (err error) {
+	if err := os.MkdirAll(l.Dir, 0o755); err != nil {
+		return fmt.Errorf("create gallery dir: %w", err)
+	}
+	if err := os.MkdirAll(l.SourceDir, 0o755); err != nil {
+		return fmt.Errorf("create source dir: %w", err)
+	}
+	var written []string
+	defer func() {
+		if err != nil {
+			for _, p := range written {
+				os.Remove(p)
+			}
+		}
+	}()
+
+	write := func(path string, data []byte) error {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		written = append(written, path)
+		return nil
+	}
+	encode := func(stem string, m *image.RGBA) error {
+		data, ext, err := resizer.Encode(m)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(stem), err)
+		}
+		return write(stem+ext, data)
+	}
+
+	if err := write(filepath.Join(l.SourceDir, name+strings.ToLower(ext)), original); err != nil {
+		return err
+	}
+	for _, w := range Widths {
+		if err := encode(filepath.Join(l.Dir, fmt.Sprintf("%s-%dw", name, w)), imaging.Resize(img, w)); err != nil {
+			return err
+		}
+	}
+	return encode(filepath.Join(l.Dir, name+"-thumb"), imaging.Fill(img, ThumbWidth, ThumbHeight))
+}
+
+// Remove deletes a photo's variants and original. Files already gone are
+// not an error.
+func (l *Library) Remove(name string) error {
+	stems := []string{name + "-thumb"}
+	for _, w := range Widths {
+		stems = append(stems, fmt.Sprintf("%s-%dw", name, w))
+	}
+	var paths []string
+	for _, stem := range stems {
+		// Photos saved before JPEG variants were added have WebP ones.
+		paths = append(paths, filepath.Join(l.Dir, stem+".jpg"), filepath.Join(l.Dir, stem+".webp"))
+	}
+	originals, _ := filepath.Glob(filepath.Join(l.SourceDir, name+".*"))
+	paths = append(paths, originals...)
+
+	var errs []error
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -19,6 +19,8 @@ import (
 
 	"github.com/firefly/packstring/internal/data"
 	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/spool"
+	"github.com/firefly/packstring/internal/views"
 )
 
 // AdminFuncMap returns template functions needed by admin templates.
@@ -82,12 +84,54 @@ func AdminFuncMap() template.FuncMap {
 			}
 			return a / b
 		},
+		"dateInput": func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return t.Format(db.DateLayout)
+		},
+		"tripDates": func(start, end *time.Time) string {
+			if start == nil {
+				return ""
+			}
+			if end == nil || end.Equal(*start) {
+				return start.Format("Mon, Jan 2, 2006")
+			}
+			if start.Year() == end.Year() {
+				return start.Format("Jan 2") + " – " + end.Format("Jan 2, 2006")
+			}
+			return start.Format("Jan 2, 2006") + " – " + end.Format("Jan 2, 2006")
+		},
+		"dict": func(pairs ...any) map[string]any {
+			m := make(map[string]any, len(pairs)/2)
+			for i := 0; i+1 < len(pairs); i += 2 {
+				if k, ok := pairs[i].(string); ok {
+					m[k] = pairs[i+1]
+				}
+			}
+			return m
+		},
+		"hasString": func(list []string, s string) bool {
+			for _, v := range list {
+				if v == s {
+					return true
+				}
+			}
+			return false
+		},
+		"splitLines": func(s string) []string {
+			if s == "" {
+				return nil
+			}
+			return strings.Split(s, "\n")
+		},
 		"statusLabel": func(s string) string {
 			labels := map[string]string{
 				"new":       "New",
 				"contacted": "Contacted",
 				"booked":    "Booked",
 				"archived":  "Archived",
+				"spam":      "Spam",
 			}
 			if l, ok := labels[s]; ok {
 				return l
@@ -132,7 +176,7 @@ type adminTrip struct {
 }
 
 type Admin struct {
-	templates    map[string]*template.Template
+	templates    views.Pages
 	availability *data.AvailabilityStore
 	password     string
 	sessions     map[string]time.Time // token → expiry
@@ -140,7 +184,7 @@ type Admin struct {
 	store        *db.Store
 }
 
-func NewAdmin(templates map[string]*template.Template, availability *data.AvailabilityStore, password string, store *db.Store) *Admin {
+func NewAdmin(templates views.Pages, availability *data.AvailabilityStore, password string, store *db.Store) *Admin {
 	return &Admin{
 		templates:    templates,
 		availability: availability,
@@ -150,6 +194,14 @@ func NewAdmin(templates map[string]*template.Template, availability *data.Availa
 	}
 }
 
+// siteURL returns the public base URL used in links sent to clients.
+func siteURL() string {
+	if u := os.Getenv("SITE_URL"); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return "http://localhost:8080"
+}
+
 // RequireAuth wraps a handler, redirecting to login if the session is invalid.
 func (a *Admin) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -176,6 +228,21 @@ func (a *Admin) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// EvictExpiredSessions drops expired session tokens and returns how many were removed.
+func (a *Admin) EvictExpiredSessions() int {
+	now := time.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := 0
+	for token, expiry := range a.sessions {
+		if now.After(expiry) {
+			delete(a.sessions, token)
+			n++
+		}
+	}
+	return n
+}
+
 func (a *Admin) LoginPage(w http.ResponseWriter, r *http.Request) {
 	d := map[string]any{
 		"Meta":  data.PageMeta{Title: "Admin Login — MT Hunt & Fish Outfitters"},
@@ -251,26 +318,34 @@ func (a *Admin) Logout(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
 }
 
-// Dashboard renders the admin home page with stat cards and recent inquiries.
-func (a *Admin) Dashboard(w http.ResponseWriter, r *http.Request) {
-	newCount, _ := a.store.CountInquiries("new")
-	totalCount, _ := a.store.CountInquiries("")
-	bookedCount, _ := a.store.CountInquiries("booked")
-	totalDeposits, _ := a.store.TotalDepositsCents()
-	recent, _ := a.store.RecentInquiries(5)
+// Dashboard renders the admin home page with stat cards and recent
+// inquiries, and a warning while inquiries are waiting in the spool.
+func (a *Admin) Dashboard(inquirySpool *spool.Spool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		newCount, _ := a.store.CountInquiries("new")
+		totalCount, _ := a.store.CountInquiries("")
+		bookedCount, _ := a.store.CountInquiries("booked")
+		totalDeposits, _ := a.store.TotalDepositsCents()
+		recent, _ := a.store.RecentInquiries(5)
+		spooled, err := inquirySpool.Pending()
+		if err != nil {
+			log.Printf("Error reading inquiry spool: %v", err)
+		}
 
-	d := map[string]any{
-		"Meta":          data.PageMeta{Title: "Admin Dashboard — MT Hunt & Fish Outfitters"},
-		"NewCount":      newCount,
-		"TotalCount":    totalCount,
-		"BookedCount":   bookedCount,
-		"TotalDeposits": totalDeposits,
-		"RecentInquiries": recent,
-		"ActiveNav":     "dashboard",
-	}
-	if err := a.templates["admin-dashboard"].ExecuteTemplate(w, "base.html", d); err != nil {
-		log.Printf("Error rendering dashboard: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		d := map[string]any{
+			"Meta":            data.PageMeta{Title: "Admin Dashboard — MT Hunt & Fish Outfitters"},
+			"NewCount":        newCount,
+			"TotalCount":      totalCount,
+			"BookedCount":     bookedCount,
+			"TotalDeposits":   totalDeposits,
+			"RecentInquiries": recent,
+			"Spooled":         spooled,
+			"ActiveNav":       "dashboard",
+		}
+		if err := a.templates["admin-dashboard"].ExecuteTemplate(w, "base.html", d); err != nil {
+			log.Printf("Error rendering dashboard: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
 	}
 }
 
@@ -290,6 +365,7 @@ func (a *Admin) InquiriesList(w http.ResponseWriter, r *http.Request) {
 	contactedCount, _ := a.store.CountInquiries("contacted")
 	bookedCount, _ := a.store.CountInquiries("booked")
 	archivedCount, _ := a.store.CountInquiries("archived")
+	spamCount, _ := a.store.CountInquiries("spam")
 
 	d := map[string]any{
 		"Meta":           data.PageMeta{Title: "Inquiries — MT Hunt & Fish Outfitters"},
@@ -300,6 +376,7 @@ func (a *Admin) InquiriesList(w http.ResponseWriter, r *http.Request) {
 		"ContactedCount": contactedCount,
 		"BookedCount":    bookedCount,
 		"ArchivedCount":  archivedCount,
+		"SpamCount":      spamCount,
 		"ActiveNav":      "inquiries",
 	}
 	if err := a.templates["admin-inquiries"].ExecuteTemplate(w, "base.html", d); err != nil {
@@ -336,6 +413,10 @@ func (a *Admin) InquiryDetail(w http.ResponseWriter, r *http.Request) {
 		"Inquiry":       inq,
 		"Payments":      payments,
 		"DepositConfig": depositConfig,
+		"Schedule":      a.inquiryScheduleData(inq, ""),
+		"Waivers":       a.inquiryWaiversData(inq),
+		"Manifest":      a.inquiryManifestData(inq),
+		"Licenses":      a.inquiryLicensesData(inq),
 		"ActiveNav":     "inquiries",
 	}
 	if err := a.templates["admin-inquiry-detail"].ExecuteTemplate(w, "base.html", d); err != nil {
@@ -498,17 +579,13 @@ func (a *Admin) GenerateDepositLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	siteURL := os.Getenv("SITE_URL")
-	if siteURL == "" {
-		siteURL = "http://localhost:8080"
-	}
-
+	base := siteURL()
 	checkoutURL, sessionID, err := CreateCheckoutSession(
 		inq.Email,
 		depositConfig.AmountCents,
 		inq.TripName,
-		siteURL+"/payments/success",
-		siteURL+"/payments/cancel",
+		base+"/payments/success",
+		base+"/payments/cancel",
 		id,
 	)
 	if err != nil {
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+)
+
+// analyticsPeriods are the analytics page's date ranges in days.
+var analyticsPeriods = []int{7, 30, 90, 365}
+
+// tripInterest compares how often a trip was looked at with how often it
+// was inquired about.
+type tripInterest struct {
+	tripMeta
+	Views     int // views of the category page listing the trip
+	FormOpens int // contact form opened for the trip
+	Inquiries int
+}
+
+// InquiryRate returns inquiries per hundred views of the trip.
+func (t tripInterest) InquiryRate() int {
+	if t.Views == 0 {
+		return 0
+	}
+	return t.Inquiries * 100 / t.Views
+}
+
+// analyticsBar is one day of the chart, with its height as a percent of
+// the busiest day.
+type analyticsBar struct {
+	db.AnalyticsDay
+	Height int
+}
+
+// AnalyticsPage shows the built-in page view counts: daily totals, top
+// pages and referrers, devices, and trips viewed versus inquired.
+func (a *Admin) AnalyticsPage(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if n, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil {
+		for _, p := range analyticsPeriods {
+			if n == p {
+				days = n
+			}
+		}
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, 1-days)
+
+	dailies, err := a.store.AnalyticsDays(since)
+	if err != nil {
+		log.Printf("Error loading analytics: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	top := map[string][]db.AnalyticsRow{}
+	for _, q := range []struct {
+		kind  string
+		limit int
+	}{{"page", 20}, {"referrer", 20}, {"device", 0}, {"trip_view", 0}, {"trip_contact", 0}} {
+		rows, err := a.store.AnalyticsTop(q.kind, since, q.limit)
+		if err != nil {
+			log.Printf("Error loading analytics: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		top[q.kind] = rows
+	}
+	inquiries, err := a.store.InquiriesByTrip(since)
+	if err != nil {
+		log.Printf("Error loading analytics: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Every day in the period gets a bar, including quiet ones.
+	var total db.AnalyticsDay
+	peak := 1
+	byDay := map[time.Time]db.AnalyticsDay{}
+	for _, d := range dailies {
+		byDay[d.Day.UTC()] = d
+		total.Views += d.Views
+		total.Visitors += d.Visitors
+		peak = max(peak, d.Views)
+	}
+	series := make([]analyticsBar, 0, days)
+	for d := since; !d.After(today); d = d.AddDate(0, 0, 1) {
+		day := byDay[d]
+		day.Day = d
+		series = append(series, analyticsBar{AnalyticsDay: day, Height: day.Views * 100 / peak})
+	}
+
+	trips := make([]tripInterest, len(allTrips))
+	for i, t := range allTrips {
+		trips[i] = tripInterest{tripMeta: t, Inquiries: inquiries[t.Slug]}
+		for _, row := range top["trip_view"] {
+			if row.Key == t.Slug {
+				trips[i].Views = row.Views
+			}
+		}
+		for _, row := range top["trip_contact"] {
+			if row.Key == t.Slug {
+				trips[i].FormOpens = row.Views
+			}
+		}
+	}
+
+	d := map[string]any{
+		"Meta":      data.PageMeta{Title: "Analytics — MT Hunt & Fish Outfitters"},
+		"Total":     total,
+		"Series":    series,
+		"Pages":     top["page"],
+		"Referrers": top["referrer"],
+		"Devices":   top["device"],
+		"Trips":     trips,
+		"Days":      days,
+		"Periods":   analyticsPeriods,
+		"ActiveNav": "analytics",
+	}
+	if err := a.templates["admin-analytics"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering analytics: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
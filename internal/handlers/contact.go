@@ -1,22 +1,36 @@
 package handlers
 
 import (
-	"html/template"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 
+	"github.com/firefly/packstring/internal/attribution"
 	"github.com/firefly/packstring/internal/data"
 	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/mail"
+	"github.com/firefly/packstring/internal/metrics"
+	"github.com/firefly/packstring/internal/middleware"
+	"github.com/firefly/packstring/internal/spam"
+	"github.com/firefly/packstring/internal/spool"
+	"github.com/firefly/packstring/internal/views"
 )
 
 type Contact struct {
-	templates map[string]*template.Template
-	store     *db.Store // nil if no database configured
+	templates views.Pages
+	store     *db.Store    // nil if no database configured
+	spool     *spool.Spool // where inquiries go when the database fails
+	scorer    *spam.Scorer
+	mailer    mail.Sender
+	alertTo   string // outfitter address alerted when an inquiry is spooled; "" to only log
+	alerts    sync.WaitGroup
 }
 
-func NewContact(templates map[string]*template.Template, store *db.Store) *Contact {
-	return &Contact{templates: templates, store: store}
+func NewContact(templates views.Pages, store *db.Store, inquirySpool *spool.Spool, scorer *spam.Scorer, mailer mail.Sender, alertTo string) *Contact {
+	return &Contact{templates: templates, store: store, spool: inquirySpool, scorer: scorer, mailer: mailer, alertTo: alertTo}
 }
 
 func (c *Contact) Submit(w http.ResponseWriter, r *http.Request) {
@@ -25,70 +39,138 @@ func (c *Contact) Submit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Honeypot check — if the hidden "website" field has a value, it's a bot
-	if r.FormValue("website") != "" {
-		// Silently return success to avoid tipping off the bot
-		c.renderSuccess(w, data.ContactSuccessData{})
+	form := data.ParseContactForm(r.Form)
+	if !form.Valid() {
+		metrics.Inquiries.Inc("invalid")
+		c.renderForm(w, form)
 		return
 	}
-
-	// Validate required fields
-	name := strings.TrimSpace(r.FormValue("name"))
-	email := strings.TrimSpace(r.FormValue("email"))
-
-	var errors []string
-	if name == "" {
-		errors = append(errors, "Name is required.")
-	}
-	if email == "" {
-		errors = append(errors, "Email is required.")
-	} else if !strings.Contains(email, "@") || !strings.Contains(email, ".") {
-		errors = append(errors, "Please enter a valid email address.")
-	}
-
-	if len(errors) > 0 {
-		c.renderErrors(w, errors)
+	tripName := data.TripDisplayName(form.Trip)
+
+	// Suspected spam is kept in quarantine and the sender sees the usual
+	// thank-you, so a bot learns nothing and a real client caught by
+	// mistake can be released from the admin.
+	verdict := c.scorer.Score(r.Context(), spam.Submission{
+		IP:       middleware.GetClientIP(r),
+		Email:    form.Email,
+		Name:     form.Name,
+		Message:  form.Message,
+		Token:    form.Token,
+		Honeypot: r.FormValue("website"),
+		Captcha:  r.FormValue("captcha_response"),
+	})
+	if verdict.Limited {
+		metrics.Inquiries.Inc("rate_limited")
+		log.Printf("[contact] refused inquiry from %s <%s>: too many from %s", form.Name, form.Email, middleware.GetClientIP(r))
+		form.FormError = "We've had a lot of inquiries from your connection in the last few minutes. Please wait a bit and try again, or call Forrest at (406) 459-5352."
+		c.renderForm(w, form)
 		return
 	}
 
-	tripSlug := strings.TrimSpace(r.FormValue("trip"))
-	phone := strings.TrimSpace(r.FormValue("phone"))
-	dates := strings.TrimSpace(r.FormValue("dates"))
-	partySize := strings.TrimSpace(r.FormValue("party_size"))
-	experience := strings.TrimSpace(r.FormValue("experience"))
-	message := strings.TrimSpace(r.FormValue("message"))
-	tripName := data.TripDisplayName(tripSlug)
-
 	// Store in database if available
 	if c.store != nil {
 		inq := &db.Inquiry{
-			Name:       name,
-			Email:      email,
-			Phone:      phone,
-			TripSlug:   tripSlug,
-			TripName:   tripName,
-			Dates:      dates,
-			PartySize:  partySize,
-			Experience: experience,
-			Message:    message,
+			Name:        form.Name,
+			Email:       form.Email,
+			Phone:       form.Phone,
+			TripSlug:    form.Trip,
+			TripName:    tripName,
+			Dates:       form.Dates,
+			PartySize:   form.PartySize,
+			Experience:  form.Experience,
+			Message:     form.Message,
+			Attribution: attribution.FromRequest(r),
+		}
+		if verdict.Spam() {
+			inq.Status = "spam"
+			inq.SpamReasons = strings.Join(verdict.Reasons, "\n")
 		}
 		id, err := c.store.CreateInquiry(inq)
 		if err != nil {
 			log.Printf("[contact] DB error: %v", err)
+			metrics.DBErrors.Inc("create_inquiry")
+			if !c.spoolInquiry(inq, err) {
+				form.FormError = "We couldn't send your inquiry just now. Please try again in a few minutes, or call Forrest at (406) 459-5352."
+				c.renderForm(w, form)
+				return
+			}
+		} else if verdict.Spam() {
+			metrics.Inquiries.Inc("spam")
+			log.Printf("[contact] inquiry #%d from %s <%s> quarantined as spam (score %d): %s", id, form.Name, form.Email, verdict.Score, strings.Join(verdict.Reasons, "; "))
 		} else {
-			log.Printf("[contact] inquiry #%d from %s <%s> — trip: %s", id, name, email, tripSlug)
+			metrics.Inquiries.Inc("saved")
+			log.Printf("[contact] inquiry #%d from %s <%s> — trip: %s", id, form.Name, form.Email, form.Trip)
 		}
 	} else {
-		log.Printf("Contact inquiry from %s <%s> — trip: %s", name, email, tripSlug)
+		log.Printf("Contact inquiry from %s <%s> — trip: %s (spam score %d)", form.Name, form.Email, form.Trip, verdict.Score)
 	}
 
 	c.renderSuccess(w, data.ContactSuccessData{
-		Name:      name,
-		Email:     email,
+		Name:      form.Name,
+		Email:     form.Email,
 		Trip:      tripName,
-		Dates:     dates,
-		PartySize: partySize,
+		Dates:     form.Dates,
+		PartySize: form.PartySize,
+	})
+}
+
+// spoolInquiry keeps an inquiry the database refused and alerts the
+// outfitter. It reports whether the inquiry is safe; if it isn't, the
+// whole inquiry is logged as a last resort and the client must be told.
+func (c *Contact) spoolInquiry(inq *db.Inquiry, cause error) bool {
+	if err := c.spool.Append(inq, cause); err != nil {
+		metrics.Inquiries.Inc("failed")
+		raw, _ := json.Marshal(inq)
+		log.Printf("[contact] LOST inquiry, spool failed too: %v; inquiry: %s", err, raw)
+		return false
+	}
+	metrics.Inquiries.Inc("spooled")
+	log.Printf("[contact] inquiry from %s <%s> spooled to %s", inq.Name, inq.Email, c.spool.Path())
+
+	if inq.Status == "spam" {
+		// Quarantined anyway; not worth interrupting the outfitter for.
+		return true
+	}
+	if c.alertTo == "" {
+		log.Printf("[contact] OUTFITTER_EMAIL not set — no alert sent for spooled inquiry")
+		return true
+	}
+	// Sent in the background so the client isn't kept waiting on SMTP.
+	msg := spoolAlert(c.alertTo, inq, cause)
+	c.alerts.Go(func() {
+		if err := c.mailer.Send(msg); err != nil {
+			log.Printf("[contact] spool alert failed: %v", err)
+		}
 	})
+	return true
+}
+
+// Wait blocks until spool alerts already being sent have gone out.
+func (c *Contact) Wait() {
+	c.alerts.Wait()
+}
+
+// spoolAlert tells the outfitter an inquiry is waiting in the spool, with
+// its details so they can reply before it's replayed.
+func spoolAlert(to string, inq *db.Inquiry, cause error) mail.Message {
+	var b strings.Builder
+	b.WriteString("An inquiry came in but couldn't be saved to the database, so it was\n")
+	b.WriteString("set aside. It will be added to your inquiries automatically once the\n")
+	b.WriteString("database is working again. Here it is in the meantime:\n\n")
+	fmt.Fprintf(&b, "Name:        %s\n", inq.Name)
+	fmt.Fprintf(&b, "Email:       %s\n", inq.Email)
+	fmt.Fprintf(&b, "Phone:       %s\n", inq.Phone)
+	fmt.Fprintf(&b, "Trip:        %s\n", inq.TripName)
+	fmt.Fprintf(&b, "Dates:       %s\n", inq.Dates)
+	fmt.Fprintf(&b, "Party size:  %s\n", inq.PartySize)
+	fmt.Fprintf(&b, "Experience:  %s\n", inq.Experience)
+	fmt.Fprintf(&b, "Message:\n%s\n\n", inq.Message)
+	fmt.Fprintf(&b, "Database error: %v\n", cause)
+	return mail.Message{
+		To:      to,
+		Subject: "Inquiry from " + inq.Name + " is waiting to be saved",
+		Body:    b.String(),
+	}
 }
 
 func (c *Contact) renderSuccess(w http.ResponseWriter, successData data.ContactSuccessData) {
@@ -98,21 +180,12 @@ func (c *Contact) renderSuccess(w http.ResponseWriter, successData data.ContactS
 	}
 }
 
-func (c *Contact) renderErrors(w http.ResponseWriter, errors []string) {
-	// Return an OOB swap targeting #form-errors so the form itself is preserved.
-	// htmx swaps this into the existing #form-errors div without touching the rest of the form.
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-	var b strings.Builder
-	b.WriteString(`<div id="form-errors" hx-swap-oob="true" class="bg-cream border border-copper/30 rounded-[4px] p-4 mb-6">`)
-	b.WriteString(`<p class="font-ui text-[11px] uppercase tracking-[0.35em] text-copper mb-2">Please fix the following</p>`)
-	b.WriteString(`<ul class="font-body text-ink-mid text-sm space-y-1">`)
-	for _, e := range errors {
-		b.WriteString(`<li>`)
-		b.WriteString(template.HTMLEscapeString(e))
-		b.WriteString(`</li>`)
+// renderForm re-renders the form with what the client typed and a message
+// beside each field that needs fixing. It replaces the form in place, so
+// the client doesn't lose their message.
+func (c *Contact) renderForm(w http.ResponseWriter, form data.ContactForm) {
+	if err := c.templates["contact"].ExecuteTemplate(w, "contact-form", form); err != nil {
+		log.Printf("Error rendering contact form: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
-	b.WriteString(`</ul></div>`)
-
-	w.Write([]byte(b.String()))
 }
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/middleware"
+	"github.com/firefly/packstring/internal/views"
+)
+
+// errorCopy is what each error page says, in the site's voice. Statuses
+// without an entry use the 4xx or 5xx default.
+var errorCopy = map[int]struct{ Heading, Message string }{
+	http.StatusNotFound: {
+		"Wrong Trail",
+		"Let's get you back on track. The page you were after has moved or never existed.",
+	},
+	http.StatusMethodNotAllowed: {
+		"Can't Do That Here",
+		"This page doesn't take that kind of request. Head back and try it from the site.",
+	},
+	http.StatusBadRequest: {
+		"That Didn't Come Through Right",
+		"Something in the request didn't make sense to us. Go back and give it another try.",
+	},
+	http.StatusInternalServerError: {
+		"Something Broke on Our End",
+		"It's been logged and we'll get it fixed. Try again in a minute.",
+	},
+}
+
+// problem is an RFC 9457 problem details object.
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Errors renders error responses: the branded page in base.html for
+// browsers, a fragment for htmx and problem+json for JSON clients.
+type Errors struct {
+	templates views.Pages
+}
+
+func NewErrors(templates views.Pages) *Errors {
+	return &Errors{templates: templates}
+}
+
+// Render writes an error response for status. detail, the handler's own
+// message, is shown for client errors; server errors never repeat it.
+func (e *Errors) Render(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	if status >= 500 {
+		detail = ""
+	}
+	text, ok := errorCopy[status]
+	if !ok {
+		text = errorCopy[http.StatusBadRequest]
+		if status >= 500 {
+			text = errorCopy[http.StatusInternalServerError]
+		}
+	}
+	requestID := middleware.GetRequestID(r.Context())
+	h := w.Header()
+	h.Set("Cache-Control", "no-store")
+
+	if wantsJSON(r) {
+		h.Set("Content-Type", "application/problem+json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    detail,
+			Instance:  r.URL.Path,
+			RequestID: requestID,
+		})
+		return
+	}
+
+	d := map[string]any{
+		"Meta":      data.PageMeta{Title: text.Heading + " — MT Hunt & Fish Outfitters"},
+		"Status":    status,
+		"Heading":   text.Heading,
+		"Message":   text.Message,
+		"Detail":    detail,
+		"RequestID": requestID,
+	}
+	name := "base.html"
+	if r.Header.Get("HX-Request") == "true" {
+		// htmx doesn't swap error responses unless asked; base.html
+		// swaps ones carrying this header into the request's target.
+		name = "error-fragment"
+		h.Set("X-Error-Fragment", "1")
+	}
+	// Rendered to a buffer first: this runs when something has already
+	// gone wrong, and a failure here still needs a status line.
+	var buf bytes.Buffer
+	if err := e.templates["error"].ExecuteTemplate(&buf, name, d); err != nil {
+		log.Printf("Error rendering error page: %v", err)
+		h.Del("X-Error-Fragment")
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// ServerError renders the 500 page; the recovery middleware shows it after
+// a panic.
+func (e *Errors) ServerError(w http.ResponseWriter, r *http.Request) {
+	e.Render(w, r, http.StatusInternalServerError, "")
+}
+
+// wantsJSON reports whether the client asked for JSON ahead of HTML.
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	j := strings.Index(accept, "json")
+	if j < 0 {
+		return false
+	}
+	h := strings.Index(accept, "text/html")
+	return h < 0 || j < h
+}
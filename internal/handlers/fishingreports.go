@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+)
+
+// datetimeInputLayout matches the value of an <input type="datetime-local">.
+const datetimeInputLayout = "2006-01-02T15:04"
+
+// FishingReportsPage lists every report written in the admin.
+func (a *Admin) FishingReportsPage(w http.ResponseWriter, r *http.Request) {
+	reports, err := a.store.ListFishingReports()
+	if err != nil {
+		log.Printf("Error loading fishing reports: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	d := map[string]any{
+		"Meta":      data.PageMeta{Title: "Fishing Reports — MT Hunt & Fish Outfitters"},
+		"Reports":   reports,
+		"Now":       time.Now(),
+		"ActiveNav": "reports",
+	}
+	if err := a.templates["admin-fishing-reports"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering fishing reports: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// FishingReportEditor shows the editor for a new report or an existing one.
+func (a *Admin) FishingReportEditor(w http.ResponseWriter, r *http.Request) {
+	report := &db.FishingReport{Status: "draft"}
+	if idStr := r.PathValue("id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid report ID", http.StatusBadRequest)
+			return
+		}
+		report, err = a.store.GetFishingReport(id)
+		if err != nil {
+			log.Printf("Error loading fishing report: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if report == nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	a.renderFishingReportEditor(w, report, "")
+}
+
+func (a *Admin) renderFishingReportEditor(w http.ResponseWriter, report *db.FishingReport, errMsg string) {
+	title := "New Fishing Report"
+	if report.ID != 0 {
+		title = "Edit Fishing Report"
+	}
+	publishAt := ""
+	if report.PublishedAt != nil {
+		publishAt = report.PublishedAt.Local().Format(datetimeInputLayout)
+	}
+	d := map[string]any{
+		"Meta":      data.PageMeta{Title: title + " — MT Hunt & Fish Outfitters"},
+		"Heading":   title,
+		"Report":    report,
+		"PublishAt": publishAt,
+		"Hatches":   strings.Join(report.Hatches, "\n"),
+		"Flies":     strings.Join(report.Flies, "\n"),
+		"Waters":    data.Waters(),
+		"Now":       time.Now(),
+		"Error":     errMsg,
+		"ActiveNav": "reports",
+	}
+	if errMsg != "" {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	if err := a.templates["admin-fishing-report"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering fishing report editor: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// fishingReportFromForm reads the editor fields into a report.
+func fishingReportFromForm(r *http.Request) (*db.FishingReport, string) {
+	report := &db.FishingReport{
+		Slug:    strings.TrimSpace(r.FormValue("slug")),
+		Title:   strings.TrimSpace(r.FormValue("title")),
+		River:   strings.TrimSpace(r.FormValue("river")),
+		Hatches: trimLines(r.FormValue("hatches")),
+		Flies:   trimLines(r.FormValue("flies")),
+		Body:    strings.ReplaceAll(r.FormValue("body"), "\r\n", "\n"),
+		Status:  r.FormValue("status"),
+	}
+
+	var problem string
+	if v := strings.TrimSpace(r.FormValue("flow_cfs")); v != "" {
+		n, err := strconv.Atoi(strings.ReplaceAll(v, ",", ""))
+		if err != nil || n < 0 {
+			problem = "Flow must be a whole number of CFS."
+		} else {
+			report.FlowCFS = &n
+		}
+	}
+	if v := strings.TrimSpace(r.FormValue("water_temp_f")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 32 || n > 100 {
+			problem = "Water temperature must be between 32 and 100 °F."
+		} else {
+			report.WaterTempF = &n
+		}
+	}
+	if v := r.FormValue("publish_at"); v != "" {
+		t, err := time.ParseInLocation(datetimeInputLayout, v, time.Local)
+		if err != nil {
+			problem = "Publish date isn't valid."
+		} else {
+			report.PublishedAt = &t
+		}
+	}
+	return report, problem
+}
+
+// trimLines splits a textarea value into its non-blank lines.
+func trimLines(s string) []string {
+	var out []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// SaveFishingReport creates or updates a report from the editor form.
+func (a *Admin) SaveFishingReport(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	report, problem := fishingReportFromForm(r)
+	if idStr := r.PathValue("id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid report ID", http.StatusBadRequest)
+			return
+		}
+		report.ID = id
+	}
+	if problem != "" {
+		a.renderFishingReportEditor(w, report, problem)
+		return
+	}
+
+	id, err := a.store.SaveFishingReport(report)
+	if err != nil {
+		log.Printf("Error saving fishing report: %v", err)
+		a.renderFishingReportEditor(w, report, "Couldn't save: "+err.Error())
+		return
+	}
+	http.Redirect(w, r, "/admin/fishing-reports/"+strconv.FormatInt(id, 10), http.StatusSeeOther)
+}
+
+// DeleteFishingReport removes a report and returns to the list.
+func (a *Admin) DeleteFishingReport(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid report ID", http.StatusBadRequest)
+		return
+	}
+	if err := a.store.DeleteFishingReport(id); err != nil {
+		log.Printf("Error deleting fishing report: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/admin/fishing-reports/", http.StatusSeeOther)
+}
+
+// PreviewFishingReport renders the editor form as the public page would show
+// it (htmx partial).
+func (a *Admin) PreviewFishingReport(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	report, _ := fishingReportFromForm(r)
+	if report.River == "" {
+		report.River = "River"
+	}
+	post := reportPost(*report)
+	if post.Date.IsZero() {
+		post.Date = time.Now()
+	}
+	d := map[string]any{
+		"Post": post,
+	}
+	if err := a.templates["admin-fishing-report"].ExecuteTemplate(w, "fishing-report-preview", d); err != nil {
+		log.Printf("Error rendering fishing report preview: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/gallery"
+	"github.com/firefly/packstring/internal/imaging"
+)
+
+// maxPhotoUpload caps the size of a single gallery upload.
+const maxPhotoUpload = 25 << 20
+
+// uploadTimeout is how long a gallery upload may take to arrive.
+const uploadTimeout = 5 * time.Minute
+
+// adminPhoto is a gallery photo with its image path and place in the order
+// for the manager.
+type adminPhoto struct {
+	db.GalleryPhoto
+	Src         string
+	First, Last bool
+}
+
+// GalleryPage lists the gallery's photos for captioning, reordering and
+// deleting, with the upload form.
+func (a *Admin) GalleryPage(w http.ResponseWriter, r *http.Request) {
+	a.renderGallery(w, http.StatusOK, "", nil)
+}
+
+// renderGallery shows the manager. A failed upload comes back with its
+// message and the fields the user typed.
+func (a *Admin) renderGallery(w http.ResponseWriter, status int, errMsg string, upload *db.GalleryPhoto) {
+	photos, err := a.store.ListGalleryPhotos()
+	if err != nil {
+		log.Printf("Error loading gallery photos: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	rows := make([]adminPhoto, len(photos))
+	for i, p := range photos {
+		rows[i] = adminPhoto{
+			GalleryPhoto: p,
+			Src:          gallery.Src(p.Name),
+			First:        i == 0,
+			Last:         i == len(photos)-1,
+		}
+	}
+	if upload == nil {
+		upload = &db.GalleryPhoto{Category: db.GalleryCategories[0]}
+	}
+	d := map[string]any{
+		"Meta":       data.PageMeta{Title: "Gallery — MT Hunt & Fish Outfitters"},
+		"Photos":     rows,
+		"Categories": data.GalleryCategories,
+		"Upload":     upload,
+		"Error":      errMsg,
+		"ActiveNav":  "gallery",
+	}
+	w.WriteHeader(status)
+	if err := a.templates["admin-gallery"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering gallery manager: %v", err)
+	}
+}
+
+// UploadPhoto processes an uploaded photo into the gallery's variants and
+// adds it at the end of the gallery.
+func (a *Admin) UploadPhoto(lib *gallery.Library) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// A full-size photo over a slow connection outlasts the server's
+		// read timeout, and processing it takes a while too.
+		rc := http.NewResponseController(w)
+		rc.SetReadDeadline(time.Now().Add(uploadTimeout))
+		rc.SetWriteDeadline(time.Now().Add(uploadTimeout + time.Minute))
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxPhotoUpload+1<<20)
+		if err := r.ParseMultipartForm(8 << 20); err != nil {
+			a.renderGallery(w, http.StatusRequestEntityTooLarge, "That file is too large. Photos can be up to 25 MB.", nil)
+			return
+		}
+		photo := &db.GalleryPhoto{
+			Alt:      strings.TrimSpace(r.FormValue("alt")),
+			Caption:  strings.TrimSpace(r.FormValue("caption")),
+			Category: r.FormValue("category"),
+		}
+		// Check the cheap fields before spending time on the image.
+		if photo.Alt == "" {
+			a.renderGallery(w, http.StatusUnprocessableEntity, "Describe the photo in the alt text. Screen readers and search engines rely on it.", photo)
+			return
+		}
+
+		file, header, err := r.FormFile("photo")
+		if err != nil {
+			a.renderGallery(w, http.StatusUnprocessableEntity, "Choose a photo to upload.", photo)
+			return
+		}
+		defer file.Close()
+		original, err := io.ReadAll(io.LimitReader(file, maxPhotoUpload+1))
+		if err != nil || len(original) > maxPhotoUpload {
+			a.renderGallery(w, http.StatusRequestEntityTooLarge, "That file is too large. Photos can be up to 25 MB.", photo)
+			return
+		}
+		img, format, err := imaging.Decode(bytes.NewReader(original))
+		if err != nil {
+			log.Printf("[gallery] rejected %s: %v", header.Filename, err)
+			a.renderGallery(w, http.StatusUnprocessableEntity, "That file isn't a JPEG, PNG or GIF we can read.", photo)
+			return
+		}
+
+		if err := a.store.CreateGalleryPhoto(photo); err != nil {
+			log.Printf("Error creating gallery photo: %v", err)
+			a.renderGallery(w, http.StatusUnprocessableEntity, "Couldn't save: "+err.Error(), photo)
+			return
+		}
+		start := time.Now()
+		ext := strings.ToLower(filepath.Ext(header.Filename))
+		if ext == "" {
+			ext = "." + format
+		}
+		if err := lib.Save(photo.Name, original, ext, img); err != nil {
+			log.Printf("Error processing gallery photo %s: %v", photo.Name, err)
+			if err := a.store.DeleteGalleryPhoto(photo.ID); err != nil {
+				log.Printf("Error removing gallery photo %d: %v", photo.ID, err)
+			}
+			a.renderGallery(w, http.StatusInternalServerError, "Couldn't process that photo. Try again, or try a different file.", photo)
+			return
+		}
+		b := img.Bounds()
+		log.Printf("[gallery] added %s (%d×%d %s) in %s", photo.Name, b.Dx(), b.Dy(), format, time.Since(start).Round(time.Millisecond))
+		http.Redirect(w, r, fmt.Sprintf("/admin/gallery/#photo-%d", photo.ID), http.StatusSeeOther)
+	}
+}
+
+// UpdatePhoto saves a photo's alt text, caption and category (htmx).
+func (a *Admin) UpdatePhoto(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid photo ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	photo := &db.GalleryPhoto{
+		ID:       id,
+		Alt:      r.FormValue("alt"),
+		Caption:  r.FormValue("caption"),
+		Category: r.FormValue("category"),
+	}
+	if err := a.store.UpdateGalleryPhoto(photo); err != nil {
+		log.Printf("Error updating gallery photo: %v", err)
+		trigger, _ := json.Marshal(map[string]string{"showToast": "Couldn't save: " + err.Error()})
+		w.Header().Set("HX-Trigger", string(trigger))
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("HX-Trigger", `{"showToast": "Photo saved"}`)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MovePhoto moves a photo one place earlier or later in the gallery.
+func (a *Admin) MovePhoto(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid photo ID", http.StatusBadRequest)
+		return
+	}
+	if err := a.store.MoveGalleryPhoto(id, r.FormValue("dir") == "up"); err != nil {
+		log.Printf("Error moving gallery photo: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/admin/gallery/#photo-%d", id), http.StatusSeeOther)
+}
+
+// DeletePhoto removes a photo from the gallery along with its files.
+func (a *Admin) DeletePhoto(lib *gallery.Library) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid photo ID", http.StatusBadRequest)
+			return
+		}
+		photo, err := a.store.GetGalleryPhoto(id)
+		if err != nil {
+			log.Printf("Error loading gallery photo: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if photo == nil {
+			http.NotFound(w, r)
+			return
+		}
+		if err := a.store.DeleteGalleryPhoto(id); err != nil {
+			log.Printf("Error deleting gallery photo: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if err := lib.Remove(photo.Name); err != nil {
+			log.Printf("[gallery] removing files for %s: %v", photo.Name, err)
+		}
+		http.Redirect(w, r, "/admin/gallery/", http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/metrics"
+	"github.com/firefly/packstring/internal/views"
+)
+
+// readyTimeout bounds each readiness check, so a locked database makes the
+// probe fail rather than hang.
+const readyTimeout = 2 * time.Second
+
+// Healthz reports that the process is up and serving. It checks nothing
+// else, so a load balancer doesn't restart the server over a slow database.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// Readyz reports whether the server can do its job: SQLite answers and
+// every page template is loaded. Each check is listed in the response.
+func Readyz(store *db.Store, loader *views.Loader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		defer cancel()
+
+		checks := []struct {
+			name string
+			err  error
+		}{
+			{"database", store.Ping(ctx)},
+			{"templates", loader.Check()},
+		}
+		status := http.StatusOK
+		body := ""
+		for _, c := range checks {
+			if c.err != nil {
+				log.Printf("[health] %s not ready: %v", c.name, c.err)
+				status = http.StatusServiceUnavailable
+				body += c.name + ": " + c.err.Error() + "\n"
+			} else {
+				body += c.name + ": ok\n"
+			}
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}
+}
+
+// Metrics serves the registry for Prometheus. With a token set, scrapes
+// must send it as a bearer token; without one the endpoint is open, which
+// suits a scraper on a private network.
+func Metrics(reg *metrics.Registry, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			got := r.Header.Get("Authorization")
+			if subtle.ConstantTimeCompare([]byte(got), []byte("Bearer "+token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		reg.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/firefly/packstring/internal/charts"
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+)
+
+// seasonTotals sums a season's months, or its trips for lead time.
+type seasonTotals struct {
+	Inquiries    int
+	Bookings     int
+	DepositCents int64
+	LeadDays     float64
+	LeadCount    int // inquiries with a trip date, which LeadDays averages over
+}
+
+// ConversionRate returns the share of inquiries that booked, as a whole
+// percent.
+func (t seasonTotals) ConversionRate() int {
+	if t.Inquiries == 0 {
+		return 0
+	}
+	return t.Bookings * 100 / t.Inquiries
+}
+
+// yoyRow is one line of the year-over-year table.
+type yoyRow struct {
+	Label  string
+	This   string
+	Last   string
+	Change string // e.g. "+12%"; empty when last year had nothing to compare
+	Up     bool
+}
+
+// tripSeason is a trip's line in the season table.
+type tripSeason struct {
+	db.TripStat
+	Name string
+}
+
+// InsightsPage reports a season (calendar year) of inquiries, bookings and
+// deposits: monthly charts, conversion, lead time and revenue by trip, and
+// a comparison with the season before.
+func (a *Admin) InsightsPage(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	first, err := a.store.FirstInquiryYear()
+	if err != nil {
+		log.Printf("Error loading insights: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if first == 0 || first > now.Year() {
+		first = now.Year()
+	}
+	year := now.Year()
+	if n, err := strconv.Atoi(r.URL.Query().Get("year")); err == nil && n >= first && n <= now.Year() {
+		year = n
+	}
+	var years []int
+	for y := now.Year(); y >= first; y-- {
+		years = append(years, y)
+	}
+
+	this, err := a.store.MonthlyStats(year)
+	if err != nil {
+		log.Printf("Error loading insights: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	last, err := a.store.MonthlyStats(year - 1)
+	if err != nil {
+		log.Printf("Error loading insights: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	tripStats, err := a.store.TripStats(year)
+	if err != nil {
+		log.Printf("Error loading insights: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// A season still underway is compared with the same months of the one
+	// before, not all of it.
+	through := 12
+	if year == now.Year() {
+		through = int(now.Month())
+	}
+	var season, thisToDate, lastToDate seasonTotals
+	for i := range this {
+		addMonth(&season, this[i])
+		if i < through {
+			addMonth(&thisToDate, this[i])
+			addMonth(&lastToDate, last[i])
+		}
+	}
+
+	trips := make([]tripSeason, len(tripStats))
+	for i, st := range tripStats {
+		trips[i] = tripSeason{TripStat: st, Name: tripDisplayName(st.Slug)}
+		if st.LeadCount > 0 {
+			season.LeadDays += st.LeadDays * float64(st.LeadCount)
+			season.LeadCount += st.LeadCount
+		}
+	}
+	if season.LeadCount > 0 {
+		season.LeadDays /= float64(season.LeadCount)
+	}
+
+	labels := make([]string, 12)
+	inquiries := make([]float64, 12)
+	bookings := make([]float64, 12)
+	lastInquiries := make([]float64, 12)
+	deposits := make([]float64, 12)
+	lastDeposits := make([]float64, 12)
+	for i := range this {
+		labels[i] = this[i].Month.String()[:3]
+		inquiries[i] = float64(this[i].Inquiries)
+		bookings[i] = float64(this[i].Bookings)
+		lastInquiries[i] = float64(last[i].Inquiries)
+		deposits[i] = float64(this[i].DepositCents) / 100
+		lastDeposits[i] = float64(last[i].DepositCents) / 100
+	}
+	thisName, lastName := strconv.Itoa(year), strconv.Itoa(year-1)
+	dollars := func(v float64) string { return fmt.Sprintf("$%.0f", v) }
+
+	period := "Full season"
+	if through < 12 {
+		period = "Jan – " + time.Month(through).String()[:3]
+		if through == 1 {
+			period = "January"
+		}
+	}
+
+	d := map[string]any{
+		"Meta":     data.PageMeta{Title: "Insights — MT Hunt & Fish Outfitters"},
+		"Year":     year,
+		"LastYear": year - 1,
+		"Years":    years,
+		"Season":   season,
+		"Trips":    trips,
+		"Period":   period,
+		"Comparison": []yoyRow{
+			compareInt("Inquiries", thisToDate.Inquiries, lastToDate.Inquiries),
+			compareInt("Bookings", thisToDate.Bookings, lastToDate.Bookings),
+			compareRate("Conversion", thisToDate, lastToDate),
+			compareCents("Deposits", thisToDate.DepositCents, lastToDate.DepositCents),
+		},
+		"MonthlyChart": charts.Bars{
+			Title:  "Inquiries and bookings per month, " + thisName,
+			Labels: labels,
+			Series: []charts.Series{
+				{Name: "Inquiries", Color: charts.Copper, Values: inquiries},
+				{Name: "Bookings", Color: charts.Forest, Values: bookings},
+			},
+		}.SVG(),
+		"InquiriesChart": charts.Bars{
+			Title:  "Inquiries per month, " + lastName + " and " + thisName,
+			Labels: labels,
+			Series: []charts.Series{
+				{Name: lastName, Color: charts.Stone, Values: lastInquiries},
+				{Name: thisName, Color: charts.Copper, Values: inquiries},
+			},
+		}.SVG(),
+		"DepositsChart": charts.Bars{
+			Title:  "Deposits paid per month, " + lastName + " and " + thisName,
+			Labels: labels,
+			Series: []charts.Series{
+				{Name: lastName, Color: charts.Stone, Values: lastDeposits},
+				{Name: thisName, Color: charts.Forest, Values: deposits},
+			},
+			Format: dollars,
+		}.SVG(),
+		"ActiveNav": "insights",
+	}
+	if err := a.templates["admin-insights"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering insights: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func addMonth(t *seasonTotals, m db.MonthStat) {
+	t.Inquiries += m.Inquiries
+	t.Bookings += m.Bookings
+	t.DepositCents += m.DepositCents
+}
+
+// tripDisplayName returns the admin name for a trip slug. Inquiries sent
+// without picking a trip have no slug.
+func tripDisplayName(slug string) string {
+	if slug == "" {
+		return "General inquiry"
+	}
+	for _, t := range allTrips {
+		if t.Slug == slug {
+			return t.Name
+		}
+	}
+	return slug
+}
+
+func compareInt(label string, this, last int) yoyRow {
+	row := yoyRow{Label: label, This: strconv.Itoa(this), Last: strconv.Itoa(last), Up: this >= last}
+	if last > 0 {
+		row.Change = fmt.Sprintf("%+d%%", (this-last)*100/last)
+	}
+	return row
+}
+
+func compareCents(label string, this, last int64) yoyRow {
+	row := yoyRow{Label: label, This: fmt.Sprintf("$%d", this/100), Last: fmt.Sprintf("$%d", last/100), Up: this >= last}
+	if last > 0 {
+		row.Change = fmt.Sprintf("%+d%%", (this-last)*100/last)
+	}
+	return row
+}
+
+// compareRate compares conversion rates in percentage points, which read
+// more plainly than a percent change of a percent.
+func compareRate(label string, this, last seasonTotals) yoyRow {
+	t, l := this.ConversionRate(), last.ConversionRate()
+	row := yoyRow{Label: label, This: strconv.Itoa(t) + "%", Last: strconv.Itoa(l) + "%", Up: t >= l}
+	if last.Inquiries > 0 {
+		row.Change = fmt.Sprintf("%+d pts", t-l)
+	}
+	return row
+}
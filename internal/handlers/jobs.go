@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/jobs"
+)
+
+// JobsPage shows each scheduled job with its last and next run and recent history.
+func (a *Admin) JobsPage(sched *jobs.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := sched.Statuses(10)
+		if err != nil {
+			log.Printf("Error loading job statuses: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		d := map[string]any{
+			"Meta":      data.PageMeta{Title: "Jobs — MT Hunt & Fish Outfitters"},
+			"Jobs":      statuses,
+			"ActiveNav": "jobs",
+		}
+		if err := a.templates["admin-jobs"].ExecuteTemplate(w, "base.html", d); err != nil {
+			log.Printf("Error rendering jobs page: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// RunJob starts a job immediately from the admin page.
+func (a *Admin) RunJob(sched *jobs.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := sched.RunNow(name); err != nil && !errors.Is(err, jobs.ErrRunning) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("[jobs] %s started from admin", name)
+		http.Redirect(w, r, "/admin/jobs/", http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/licenses"
+)
+
+// inquiryLicensesData gathers what the inquiry-licenses partial needs.
+func (a *Admin) inquiryLicensesData(inq *db.Inquiry) map[string]any {
+	d := map[string]any{"Inquiry": inq, "Required": licenses.Required(inq)}
+	st, err := licenses.ForInquiry(a.store, inq, time.Now())
+	if err != nil {
+		log.Printf("Error loading licenses for inquiry #%d: %v", inq.ID, err)
+		return d
+	}
+	d["Status"] = st
+	d["Today"] = today()
+	return d
+}
+
+// SaveInquiryLicenses records the license and tag numbers entered on the inquiry page.
+func (a *Admin) SaveInquiryLicenses(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid inquiry ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	inq, err := a.store.GetInquiry(id)
+	if err != nil || inq == nil {
+		http.Error(w, "Inquiry not found", http.StatusNotFound)
+		return
+	}
+
+	// Each entry posts parallel hunter/type/license_number/tag_number values.
+	hunters := r.PostForm["hunter"]
+	types := r.PostForm["type"]
+	numbers := r.PostForm["license_number"]
+	tags := r.PostForm["tag_number"]
+	var entries []db.HuntLicense
+	for i := range hunters {
+		if i >= len(types) || i >= len(numbers) || i >= len(tags) {
+			break
+		}
+		pos, err := strconv.Atoi(hunters[i])
+		if err != nil || pos < 1 || pos > db.MaxPartySize {
+			continue
+		}
+		entries = append(entries, db.HuntLicense{
+			Hunter:        pos,
+			Type:          types[i],
+			LicenseNumber: strings.TrimSpace(numbers[i]),
+			TagNumber:     strings.TrimSpace(tags[i]),
+		})
+	}
+
+	if err := a.store.SaveLicenses(id, entries); err != nil {
+		log.Printf("Error saving licenses: %v", err)
+		http.Error(w, "Failed to save licenses", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": "Licenses saved"}`)
+	if err := a.templates["admin-inquiry-detail"].ExecuteTemplate(w, "inquiry-licenses", a.inquiryLicensesData(inq)); err != nil {
+		log.Printf("Error rendering inquiry licenses: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// LicensesReport lists booked hunts still missing license numbers.
+func (a *Admin) LicensesReport(w http.ResponseWriter, r *http.Request) {
+	outstanding, err := licenses.Outstanding(a.store, time.Now())
+	if err != nil {
+		log.Printf("Error loading license report: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	d := map[string]any{
+		"Meta":        data.PageMeta{Title: "Licenses — MT Hunt & Fish Outfitters"},
+		"Outstanding": outstanding,
+		"Today":       today(),
+		"ActiveNav":   "licenses",
+	}
+	if err := a.templates["admin-licenses"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering license report: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
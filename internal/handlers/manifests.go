@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/validate"
+	"github.com/firefly/packstring/internal/views"
+)
+
+// experienceLabels gives display names for guest experience levels.
+var experienceLabels = map[string]string{
+	"beginner":     "Beginner",
+	"intermediate": "Intermediate",
+	"experienced":  "Experienced",
+}
+
+// Length caps for the manifest's free-text guest fields, in characters.
+const (
+	maxGuestNameLen  = 100
+	maxGuestPhoneLen = 32
+	maxLicenseLen    = 40
+	maxDietaryLen    = 500
+)
+
+// guestTooLong returns what to tell the client if one of g's fields is over
+// its cap, or "" if they all fit.
+func guestTooLong(g db.Guest) string {
+	for _, f := range []struct {
+		value, label string
+		max          int
+	}{
+		{g.Name, "name", maxGuestNameLen},
+		{g.EmergencyName, "emergency contact's name", maxGuestNameLen},
+		{g.EmergencyPhone, "emergency contact's phone", maxGuestPhoneLen},
+		{g.LicenseNumber, "license number", maxLicenseLen},
+		{g.Dietary, "dietary notes", maxDietaryLen},
+	} {
+		if !validate.MaxLen(f.value, f.max) {
+			name := g.Name
+			if !validate.MaxLen(name, maxGuestNameLen) {
+				name = "a guest"
+			}
+			return fmt.Sprintf("Shorten the %s for %s to %d characters or fewer.", f.label, name, f.max)
+		}
+	}
+	return ""
+}
+
+// --- Admin ---
+
+// inquiryManifestData gathers what the inquiry-manifest partial needs.
+func (a *Admin) inquiryManifestData(inq *db.Inquiry) map[string]any {
+	manifest, _ := a.store.GetManifest(inq.ID)
+	guests, _ := a.store.GuestsByInquiry(inq.ID)
+	url := ""
+	if manifest != nil {
+		url = siteURL() + "/manifest/" + manifest.Token
+	}
+	return map[string]any{
+		"Inquiry":     inq,
+		"Manifest":    manifest,
+		"URL":         url,
+		"Guests":      guests,
+		"Hunting":     tripCategory(inq.TripSlug) == "Hunting",
+		"Experiences": experienceLabels,
+	}
+}
+
+// CreateManifestLink generates the lead client's manifest link for an inquiry.
+func (a *Admin) CreateManifestLink(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid inquiry ID", http.StatusBadRequest)
+		return
+	}
+	inq, err := a.store.GetInquiry(id)
+	if err != nil || inq == nil {
+		http.Error(w, "Inquiry not found", http.StatusNotFound)
+		return
+	}
+
+	token, err := newToken()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if err := a.store.CreateManifest(id, token); err != nil {
+		log.Printf("Error creating manifest: %v", err)
+		http.Error(w, "Failed to create manifest link", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": "Manifest link ready"}`)
+	if err := a.templates["admin-inquiry-detail"].ExecuteTemplate(w, "inquiry-manifest", a.inquiryManifestData(inq)); err != nil {
+		log.Printf("Error rendering inquiry manifest: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// DayManifestPage renders a printable list of everyone going out on a date.
+func (a *Admin) DayManifestPage(w http.ResponseWriter, r *http.Request) {
+	day := today()
+	if d, err := time.Parse(db.DateLayout, r.URL.Query().Get("date")); err == nil {
+		day = d
+	}
+
+	trips, err := a.store.ManifestForDay(day)
+	if err != nil {
+		log.Printf("Error loading day manifest: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	headcount := 0
+	for _, t := range trips {
+		if n := len(t.Guests); n > 0 {
+			headcount += n
+		} else {
+			headcount += t.Inquiry.Headcount()
+		}
+	}
+
+	d := map[string]any{
+		"Meta":        data.PageMeta{Title: "Manifest " + day.Format("Jan 2, 2006") + " — MT Hunt & Fish Outfitters"},
+		"Day":         day,
+		"Prev":        day.AddDate(0, 0, -1).Format(db.DateLayout),
+		"Next":        day.AddDate(0, 0, 1).Format(db.DateLayout),
+		"Trips":       trips,
+		"Headcount":   headcount,
+		"Experiences": experienceLabels,
+		"ActiveNav":   "schedule",
+	}
+	if err := a.templates["admin-manifest"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering day manifest: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// --- Public manifest form ---
+
+// Manifests serves the lead client's party manifest form behind a tokenized link.
+type Manifests struct {
+	templates views.Pages
+	store     *db.Store
+}
+
+// NewManifests creates the public manifest handler.
+func NewManifests(templates views.Pages, store *db.Store) *Manifests {
+	return &Manifests{templates: templates, store: store}
+}
+
+// load resolves a manifest token to its manifest and inquiry.
+func (h *Manifests) load(w http.ResponseWriter, r *http.Request) (*db.Manifest, *db.Inquiry, bool) {
+	manifest, err := h.store.GetManifestByToken(r.PathValue("token"))
+	if err != nil {
+		log.Printf("[manifest] lookup error: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if manifest == nil {
+		http.Error(w, "This manifest link isn't valid. Check the link or call Forrest at (406) 459-5352.", http.StatusNotFound)
+		return nil, nil, false
+	}
+	inq, err := h.store.GetInquiry(manifest.InquiryID)
+	if err != nil || inq == nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return manifest, inq, true
+}
+
+func (h *Manifests) render(w http.ResponseWriter, manifest *db.Manifest, inq *db.Inquiry, guests []db.Guest, saved bool, errMsg string) {
+	// Show one row per expected guest, with hidden spares the client can reveal.
+	shown := inq.Headcount()
+	if len(guests) > shown {
+		shown = len(guests)
+	}
+	if len(guests) == 0 {
+		guests = []db.Guest{{Name: inq.Name}}
+	}
+	rows := make([]db.Guest, db.MaxPartySize)
+	copy(rows, guests)
+	for i := range rows {
+		rows[i].Position = i + 1
+	}
+
+	d := map[string]any{
+		"Meta":        data.PageMeta{Title: "Trip Manifest — MT Hunt & Fish Outfitters"},
+		"Manifest":    manifest,
+		"Inquiry":     inq,
+		"Rows":        rows,
+		"Shown":       shown,
+		"Hunting":     tripCategory(inq.TripSlug) == "Hunting",
+		"Experiences": db.ExperienceLevels,
+		"Labels":      experienceLabels,
+		"Saved":       saved,
+		"Error":       errMsg,
+	}
+	if err := h.templates["manifest"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering manifest page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// Page shows the manifest form, prefilled with anything already submitted.
+func (h *Manifests) Page(w http.ResponseWriter, r *http.Request) {
+	manifest, inq, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	guests, err := h.store.GuestsByInquiry(inq.ID)
+	if err != nil {
+		log.Printf("[manifest] guests error: %v", err)
+	}
+	h.render(w, manifest, inq, guests, r.URL.Query().Get("saved") == "1", "")
+}
+
+// Submit saves the party. Rows without a name are ignored, and the link stays
+// usable so the client can correct details before the trip.
+func (h *Manifests) Submit(w http.ResponseWriter, r *http.Request) {
+	manifest, inq, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	field := func(name string, i int) string {
+		vals := r.PostForm[name]
+		if i < len(vals) {
+			return strings.TrimSpace(vals[i])
+		}
+		return ""
+	}
+	var guests []db.Guest
+	for i := range r.PostForm["name"] {
+		if i >= db.MaxPartySize {
+			break
+		}
+		g := db.Guest{
+			Name:           field("name", i),
+			EmergencyName:  field("emergency_name", i),
+			EmergencyPhone: field("emergency_phone", i),
+			LicenseNumber:  field("license_number", i),
+			Dietary:        field("dietary", i),
+			Experience:     field("experience", i),
+		}
+		if g.Name == "" {
+			continue
+		}
+		if _, ok := experienceLabels[g.Experience]; !ok {
+			g.Experience = ""
+		}
+		guests = append(guests, g)
+	}
+
+	if len(guests) == 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.render(w, manifest, inq, nil, false, "Add at least one person to the manifest.")
+		return
+	}
+	for _, g := range guests {
+		if msg := guestTooLong(g); msg != "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			h.render(w, manifest, inq, guests, false, msg)
+			return
+		}
+		if g.EmergencyName == "" || g.EmergencyPhone == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			h.render(w, manifest, inq, guests, false, fmt.Sprintf("Add an emergency contact for %s.", g.Name))
+			return
+		}
+	}
+
+	if err := h.store.SaveGuests(inq.ID, guests); err != nil {
+		log.Printf("[manifest] save error: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("[manifest] inquiry #%d manifest saved with %d guest(s)", inq.ID, len(guests))
+
+	http.Redirect(w, r, "/manifest/"+manifest.Token+"?saved=1", http.StatusSeeOther)
+}
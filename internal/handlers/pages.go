@@ -1,30 +1,44 @@
 package handlers
 
 import (
-	"html/template"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/gallery"
+	"github.com/firefly/packstring/internal/posts"
+	"github.com/firefly/packstring/internal/views"
 )
 
 type Pages struct {
-	templates    map[string]*template.Template
+	templates    views.Pages
 	availability *data.AvailabilityStore
+	store        *db.Store // nil if no database configured
+	posts        *posts.Store
 }
 
-func NewPages(templates map[string]*template.Template, availability *data.AvailabilityStore) *Pages {
-	return &Pages{templates: templates, availability: availability}
+func NewPages(templates views.Pages, availability *data.AvailabilityStore, store *db.Store, reports *posts.Store) *Pages {
+	return &Pages{templates: templates, availability: availability, store: store, posts: reports}
 }
 
-// attachAvailability populates the Availability field on each TripSection from the store.
+// attachAvailability populates the Availability field on each TripSection from the store,
+// marking slots booked where every guide or every boat for the trip is already committed.
 func (p *Pages) attachAvailability(trips []data.TripSection) {
+	slots := make([][]data.DateSlot, len(trips))
 	for i := range trips {
-		trips[i].Availability = p.availability.Get(trips[i].Slug)
+		slots[i] = p.availability.Get(trips[i].Slug)
+	}
+	sa := loadSlotAvailability(p.store, slots...)
+	for i := range trips {
+		trips[i].Availability = sa.derive(trips[i].Slug, slots[i])
 	}
 }
 
 func (p *Pages) HomePage(w http.ResponseWriter, r *http.Request) {
-	pageData := data.GetHomePageData()
+	pageData := data.GetHomePageData(posts.Latest(p.reports(time.Now()), 3))
 	if err := p.templates["home"].ExecuteTemplate(w, "base.html", pageData); err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -39,7 +53,10 @@ func (p *Pages) TripsHub(w http.ResponseWriter, r *http.Request) {
 
 func (p *Pages) FishingPage(w http.ResponseWriter, r *http.Request) {
 	pageData := data.GetFishingPageData()
+	now := time.Now()
 	p.attachAvailability(pageData.Trips)
+	p.attachReports(pageData.Trips, now)
+	pageData.Meta.Schema = data.TripSchemas(pageData.Trips, "Fishing", "/trips/fishing/", now)
 	if err := p.templates["fishing"].ExecuteTemplate(w, "base.html", pageData); err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -47,7 +64,10 @@ func (p *Pages) FishingPage(w http.ResponseWriter, r *http.Request) {
 
 func (p *Pages) HuntingPage(w http.ResponseWriter, r *http.Request) {
 	pageData := data.GetHuntingPageData()
+	now := time.Now()
 	p.attachAvailability(pageData.Trips)
+	p.attachReports(pageData.Trips, now)
+	pageData.Meta.Schema = data.TripSchemas(pageData.Trips, "Hunting", "/trips/hunting/", now)
 	if err := p.templates["hunting"].ExecuteTemplate(w, "base.html", pageData); err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -55,14 +75,34 @@ func (p *Pages) HuntingPage(w http.ResponseWriter, r *http.Request) {
 
 func (p *Pages) PackagesPage(w http.ResponseWriter, r *http.Request) {
 	pageData := data.GetPackagesPageData()
+	now := time.Now()
 	p.attachAvailability(pageData.Packages)
+	p.attachReports(pageData.Packages, now)
+	pageData.Meta.Schema = data.TripSchemas(pageData.Packages, "Fishing and Hunting", "/trips/packages/", now)
 	if err := p.templates["packages"].ExecuteTemplate(w, "base.html", pageData); err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
 func (p *Pages) GalleryPage(w http.ResponseWriter, r *http.Request) {
-	pageData := data.GetGalleryPageData()
+	var images []data.GalleryImage
+	if p.store != nil {
+		photos, err := p.store.ListGalleryPhotos()
+		if err != nil {
+			log.Printf("Error loading gallery photos: %v", err)
+		}
+		for _, ph := range photos {
+			images = append(images, data.GalleryImage{
+				ID:       int(ph.ID),
+				Src:      gallery.Src(ph.Name),
+				Thumb:    gallery.Thumb(ph.Name),
+				Alt:      ph.Alt,
+				Caption:  ph.Caption,
+				Category: ph.Category,
+			})
+		}
+	}
+	pageData := data.GetGalleryPageData(images)
 	if err := p.templates["gallery"].ExecuteTemplate(w, "base.html", pageData); err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
@@ -74,3 +114,28 @@ func (p *Pages) ContactPage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
+
+// GearPage renders a printable gear list and trip-prep notes for a single trip.
+func (p *Pages) GearPage(w http.ResponseWriter, r *http.Request) {
+	trip, ok := data.FindTrip(r.PathValue("slug"))
+	if !ok || len(trip.Gear) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	category := tripCategory(trip.Slug)
+	pageData := map[string]any{
+		"Meta": data.PageMeta{
+			Title:        "What to Bring: " + trip.Title + " — MT Hunt & Fish Outfitters",
+			Description:  "Gear list and trip prep for " + trip.Title + " with MT Hunt & Fish Outfitters in Helena, Montana.",
+			CanonicalURL: data.BaseURL() + "/trips/" + trip.Slug + "/gear/",
+			OGImage:      data.ImageURL(trip.Image, "800w"),
+			Breadcrumbs: data.Breadcrumbs("Trips", "/trips/",
+				category, "/trips/"+strings.ToLower(category)+"/",
+				"What to Bring", "/trips/"+trip.Slug+"/gear/"),
+		},
+		"Trip": trip,
+	}
+	if err := p.templates["gear"].ExecuteTemplate(w, "base.html", pageData); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/posts"
+)
+
+// reportsPerPage is how many reports the /reports/ listing shows per page.
+const reportsPerPage = 8
+
+// feedSize caps the number of entries in the RSS and Atom feeds.
+const feedSize = 20
+
+// tripReportMaxAge is how old a report can be and still show on a trip page.
+const tripReportMaxAge = 45 * 24 * time.Hour
+
+// reportPost converts a report written in the admin to a post.
+func reportPost(r db.FishingReport) posts.Post {
+	html := posts.Render(r.Body)
+	post := posts.Post{
+		Slug:    r.Slug,
+		Title:   r.Title,
+		Summary: posts.Summarize(html, 200),
+		Tags:    []string{r.River},
+		HTML:    html,
+		Conditions: &posts.Conditions{
+			River:      r.River,
+			FlowCFS:    r.FlowCFS,
+			WaterTempF: r.WaterTempF,
+			Hatches:    r.Hatches,
+			Flies:      r.Flies,
+		},
+		Draft: r.Status != "published",
+	}
+	if r.PublishedAt != nil {
+		post.Date = r.PublishedAt.Local()
+	} else {
+		post.Date = r.UpdatedAt.Local()
+	}
+	post.Updated = r.UpdatedAt
+	if post.Date.After(post.Updated) {
+		post.Updated = post.Date
+	}
+	return post
+}
+
+// reports returns every published report, from the admin and from Markdown
+// files, newest first.
+func (p *Pages) reports(now time.Time) []posts.Post {
+	var fromDB []posts.Post
+	if p.store != nil {
+		rows, err := p.store.PublishedFishingReports(now)
+		if err != nil {
+			log.Printf("Error loading fishing reports: %v", err)
+		}
+		for _, r := range rows {
+			fromDB = append(fromDB, reportPost(r))
+		}
+	}
+	return posts.Merge(fromDB, p.posts.Published(now))
+}
+
+// attachReports sets each trip's Report to the newest recent report on one of
+// its waters.
+func (p *Pages) attachReports(trips []data.TripSection, now time.Time) {
+	reports := p.reports(now)
+	for i := range trips {
+		trips[i].Report = nil
+	search:
+		for j := range reports {
+			c := reports[j].Conditions
+			if c == nil || now.Sub(reports[j].Date) > tripReportMaxAge {
+				continue
+			}
+			for _, loc := range trips[i].Locations {
+				if data.SameWater(c.River, loc) {
+					trips[i].Report = &reports[j]
+					break search
+				}
+			}
+		}
+	}
+}
+
+// relatedTrip links a report to a trip section on its category page.
+type relatedTrip struct {
+	Title string
+	URL   string
+}
+
+func relatedTrips(slugs []string) []relatedTrip {
+	var out []relatedTrip
+	for _, slug := range slugs {
+		trip, ok := data.FindTrip(slug)
+		if !ok {
+			continue
+		}
+		out = append(out, relatedTrip{
+			Title: trip.Title,
+			URL:   "/trips/" + strings.ToLower(tripCategory(slug)) + "/#" + slug,
+		})
+	}
+	return out
+}
+
+func reportsFeed() posts.Feed {
+	return posts.Feed{
+		Title:       "Fishing Reports — MT Hunt & Fish Outfitters",
+		Description: "River conditions, hatches and hunting notes from Helena, Montana.",
+		SiteURL:     data.BaseURL(),
+		Path:        "/reports/",
+	}
+}
+
+// ReportsPage lists published reports, newest first, with ?page=N pagination.
+func (p *Pages) ReportsPage(w http.ResponseWriter, r *http.Request) {
+	n := 1
+	if v := r.URL.Query().Get("page"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	page, ok := posts.Paginate(p.reports(time.Now()), n, reportsPerPage)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	canonical := data.BaseURL() + "/reports/"
+	title := "Fishing Reports — MT Hunt & Fish Outfitters"
+	if page.Number > 1 {
+		canonical += "?page=" + strconv.Itoa(page.Number)
+		title = "Fishing Reports, Page " + strconv.Itoa(page.Number) + " — MT Hunt & Fish Outfitters"
+	}
+	pageData := map[string]any{
+		"Meta": data.PageMeta{
+			Title:        title,
+			Description:  "Current river conditions, hatches, flies and hunting notes from Forrest on the Missouri River and around Helena, Montana.",
+			CanonicalURL: canonical,
+			OGImage:      data.BaseURL() + "/static/img/hero/hero-montana-1600w.webp",
+			Breadcrumbs:  data.Breadcrumbs("Reports", "/reports/"),
+		},
+		"Page": page,
+	}
+	if err := p.templates["reports"].ExecuteTemplate(w, "base.html", pageData); err != nil {
+		log.Printf("Error rendering reports: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// ReportPage renders a single report.
+func (p *Pages) ReportPage(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	post, ok := p.posts.Find(r.PathValue("slug"), now)
+	if p.store != nil {
+		rep, err := p.store.GetFishingReportBySlug(r.PathValue("slug"))
+		if err != nil {
+			log.Printf("Error loading fishing report: %v", err)
+		}
+		if rep != nil && rep.Live(now) {
+			post, ok = reportPost(*rep), true
+		}
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	ogImage := data.BaseURL() + "/static/img/hero/hero-montana-1600w.webp"
+	if post.Hero != "" {
+		ogImage = data.ImageURL(post.Hero, "1600w")
+	}
+	pageData := map[string]any{
+		"Meta": data.PageMeta{
+			Title:        post.Title + " — MT Hunt & Fish Outfitters",
+			Description:  post.Summary,
+			CanonicalURL: data.BaseURL() + post.URL(),
+			OGImage:      ogImage,
+			Breadcrumbs:  data.Breadcrumbs("Reports", "/reports/", post.Title, post.URL()),
+		},
+		"Post":    post,
+		"Preview": !post.Published(now),
+		"Trips":   relatedTrips(post.Trips),
+	}
+	if err := p.templates["report"].ExecuteTemplate(w, "base.html", pageData); err != nil {
+		log.Printf("Error rendering report: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// ReportsRSS serves the reports as an RSS 2.0 feed.
+func (p *Pages) ReportsRSS(w http.ResponseWriter, r *http.Request) {
+	feed := reportsFeed()
+	feed.SelfPath = "/reports/feed.xml"
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	if err := posts.WriteRSS(w, feed, posts.Latest(p.reports(time.Now()), feedSize)); err != nil {
+		log.Printf("Error writing RSS feed: %v", err)
+	}
+}
+
+// ReportsAtom serves the reports as an Atom feed.
+func (p *Pages) ReportsAtom(w http.ResponseWriter, r *http.Request) {
+	feed := reportsFeed()
+	feed.SelfPath = "/reports/atom.xml"
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	if err := posts.WriteAtom(w, feed, posts.Latest(p.reports(time.Now()), feedSize)); err != nil {
+		log.Printf("Error writing Atom feed: %v", err)
+	}
+}
@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+)
+
+// resourceKindLabels maps resource kinds to display names.
+var resourceKindLabels = map[string]string{
+	"guide":      "Guide",
+	"boat":       "Boat",
+	"raft":       "Raft",
+	"pack-stock": "Pack Stock",
+}
+
+// scheduleEntry is a booking as shown in a single schedule cell.
+type scheduleEntry struct {
+	InquiryID int64
+	Name      string
+	TripName  string
+	Resources string
+	Conflict  bool
+}
+
+// scheduleDay is one calendar day in the month view.
+type scheduleDay struct {
+	Date    time.Time
+	InMonth bool
+	Today   bool
+	Entries []scheduleEntry
+}
+
+// scheduleRow is one resource line in the week view.
+type scheduleRow struct {
+	Label string
+	Kind  string
+	Cells [][]scheduleEntry // one slice per day
+}
+
+// today returns the current date at midnight UTC, matching how trip dates are stored.
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseFormDate parses a YYYY-MM-DD form value. Empty input returns nil.
+func parseFormDate(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(db.DateLayout, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", s)
+	}
+	return &t, nil
+}
+
+// ResourcesPage lists guides and equipment with forms to add and edit them.
+func (a *Admin) ResourcesPage(w http.ResponseWriter, r *http.Request) {
+	resources, err := a.store.ListResources(false)
+	if err != nil {
+		log.Printf("Error loading resources: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	d := map[string]any{
+		"Meta":       data.PageMeta{Title: "Guides & Equipment — MT Hunt & Fish Outfitters"},
+		"Resources":  resources,
+		"Kinds":      db.ResourceKinds,
+		"KindLabels": resourceKindLabels,
+		"Trips":      allTrips,
+		"ActiveNav":  "schedule",
+	}
+	if err := a.templates["admin-resources"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering resources page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// SaveResource creates a resource, or updates one when the path carries an ID.
+func (a *Admin) SaveResource(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	res := &db.Resource{
+		Name:      strings.TrimSpace(r.FormValue("name")),
+		Kind:      r.FormValue("kind"),
+		TripSlugs: r.Form["trips"],
+		Notes:     strings.TrimSpace(r.FormValue("notes")),
+		Active:    r.FormValue("active") == "on" || r.PathValue("id") == "",
+	}
+	if idStr := r.PathValue("id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid resource ID", http.StatusBadRequest)
+			return
+		}
+		res.ID = id
+	}
+
+	if _, err := a.store.SaveResource(res); err != nil {
+		log.Printf("Error saving resource: %v", err)
+		http.Error(w, "Failed to save: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/resources/", http.StatusSeeOther)
+}
+
+// SchedulePage renders booked trips on a week or month calendar with
+// double-booked resources flagged.
+func (a *Admin) SchedulePage(w http.ResponseWriter, r *http.Request) {
+	view := r.URL.Query().Get("view")
+	if view != "month" {
+		view = "week"
+	}
+	anchor := today()
+	if d, err := parseFormDate(r.URL.Query().Get("date")); err == nil && d != nil {
+		anchor = *d
+	}
+
+	var from, to, prev, next time.Time
+	if view == "week" {
+		offset := (int(anchor.Weekday()) + 6) % 7 // Monday-based week
+		from = anchor.AddDate(0, 0, -offset)
+		to = from.AddDate(0, 0, 6)
+		prev, next = from.AddDate(0, 0, -7), from.AddDate(0, 0, 7)
+	} else {
+		first := time.Date(anchor.Year(), anchor.Month(), 1, 0, 0, 0, 0, time.UTC)
+		from = first.AddDate(0, 0, -((int(first.Weekday()) + 6) % 7))
+		last := first.AddDate(0, 1, -1)
+		to = last.AddDate(0, 0, (7-int(last.Weekday()))%7)
+		prev, next = first.AddDate(0, -1, 0), first.AddDate(0, 1, 0)
+		anchor = first
+	}
+
+	bookings, err := a.store.BookingsBetween(from, to)
+	if err != nil {
+		log.Printf("Error loading bookings: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	resources, _ := a.store.ListResources(true)
+	conflicts := db.ConflictingInquiries(bookings)
+
+	var days []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+
+	d := map[string]any{
+		"Meta":          data.PageMeta{Title: "Schedule — MT Hunt & Fish Outfitters"},
+		"View":          view,
+		"Anchor":        anchor,
+		"From":          from,
+		"To":            to,
+		"Prev":          prev.Format(db.DateLayout),
+		"Next":          next.Format(db.DateLayout),
+		"Days":          days,
+		"ConflictCount": len(conflicts),
+		"Unscheduled":   a.unscheduledBookings(),
+		"ActiveNav":     "schedule",
+	}
+	if view == "week" {
+		d["Rows"] = buildWeekRows(days, resources, bookings, conflicts)
+	} else {
+		d["Weeks"] = buildMonthWeeks(days, anchor.Month(), bookings, conflicts)
+	}
+
+	if err := a.templates["admin-schedule"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering schedule: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// unscheduledBookings returns booked inquiries that still need confirmed dates.
+func (a *Admin) unscheduledBookings() []db.Inquiry {
+	booked, _ := a.store.ListInquiries("booked")
+	var out []db.Inquiry
+	for _, inq := range booked {
+		if inq.TripStart == nil {
+			out = append(out, inq)
+		}
+	}
+	return out
+}
+
+func newScheduleEntry(b db.Booking, conflicts map[int64]bool) scheduleEntry {
+	names := make([]string, len(b.Resources))
+	for i, res := range b.Resources {
+		names[i] = res.Name
+	}
+	return scheduleEntry{
+		InquiryID: b.Inquiry.ID,
+		Name:      b.Inquiry.Name,
+		TripName:  b.Inquiry.TripName,
+		Resources: strings.Join(names, ", "),
+		Conflict:  conflicts[b.Inquiry.ID],
+	}
+}
+
+func buildWeekRows(days []time.Time, resources []db.Resource, bookings []db.Booking, conflicts map[int64]bool) []scheduleRow {
+	rows := make([]scheduleRow, 0, len(resources)+1)
+	for _, res := range resources {
+		row := scheduleRow{Label: res.Name, Kind: resourceKindLabels[res.Kind], Cells: make([][]scheduleEntry, len(days))}
+		for i, day := range days {
+			for _, b := range bookings {
+				if b.Overlaps(day, day) && holdsResource(b, res.ID) {
+					row.Cells[i] = append(row.Cells[i], newScheduleEntry(b, conflicts))
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	unassigned := scheduleRow{Label: "Unassigned", Cells: make([][]scheduleEntry, len(days))}
+	hasUnassigned := false
+	for i, day := range days {
+		for _, b := range bookings {
+			if len(b.Resources) == 0 && b.Overlaps(day, day) {
+				unassigned.Cells[i] = append(unassigned.Cells[i], newScheduleEntry(b, conflicts))
+				hasUnassigned = true
+			}
+		}
+	}
+	if hasUnassigned {
+		rows = append(rows, unassigned)
+	}
+	return rows
+}
+
+func buildMonthWeeks(days []time.Time, month time.Month, bookings []db.Booking, conflicts map[int64]bool) [][]scheduleDay {
+	now := today()
+	var weeks [][]scheduleDay
+	for i, day := range days {
+		if i%7 == 0 {
+			weeks = append(weeks, nil)
+		}
+		sd := scheduleDay{Date: day, InMonth: day.Month() == month, Today: day.Equal(now)}
+		for _, b := range bookings {
+			if b.Overlaps(day, day) {
+				sd.Entries = append(sd.Entries, newScheduleEntry(b, conflicts))
+			}
+		}
+		weeks[len(weeks)-1] = append(weeks[len(weeks)-1], sd)
+	}
+	return weeks
+}
+
+func holdsResource(b db.Booking, resourceID int64) bool {
+	for _, res := range b.Resources {
+		if res.ID == resourceID {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateInquirySchedule saves confirmed trip dates and resource assignments
+// for an inquiry and re-renders the schedule card with any conflicts.
+func (a *Admin) UpdateInquirySchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid inquiry ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	start, err := parseFormDate(r.FormValue("trip_start"))
+	if err == nil {
+		var end *time.Time
+		end, err = parseFormDate(r.FormValue("trip_end"))
+		if err == nil && start != nil && end == nil {
+			end = start // single-day trip
+		}
+		if err == nil {
+			err = a.store.UpdateInquiryDates(id, start, end)
+		}
+	}
+	if err != nil {
+		log.Printf("Error updating inquiry dates: %v", err)
+		w.Header().Set("HX-Trigger", `{"showToast": "Could not save dates"}`)
+		a.renderInquirySchedule(w, id, err.Error())
+		return
+	}
+
+	var resourceIDs []int64
+	for _, v := range r.Form["resources"] {
+		if rid, err := strconv.ParseInt(v, 10, 64); err == nil {
+			resourceIDs = append(resourceIDs, rid)
+		}
+	}
+	if err := a.store.SetInquiryResources(id, resourceIDs); err != nil {
+		log.Printf("Error assigning resources: %v", err)
+		http.Error(w, "Failed to assign resources", http.StatusInternalServerError)
+		return
+	}
+
+	conflicts, _ := a.store.InquiryConflicts(id)
+	if len(conflicts) > 0 {
+		w.Header().Set("HX-Trigger", `{"showToast": "Saved — but a resource is double-booked"}`)
+	} else {
+		w.Header().Set("HX-Trigger", `{"showToast": "Schedule saved"}`)
+	}
+	a.renderInquirySchedule(w, id, "")
+}
+
+// inquiryScheduleData gathers what the inquiry-schedule partial needs.
+func (a *Admin) inquiryScheduleData(inq *db.Inquiry, errMsg string) map[string]any {
+	resources, _ := a.store.ListResources(true)
+	assigned, _ := a.store.InquiryResources(inq.ID)
+	conflicts, _ := a.store.InquiryConflicts(inq.ID)
+
+	assignedIDs := make(map[int64]bool, len(assigned))
+	for _, res := range assigned {
+		assignedIDs[res.ID] = true
+	}
+	// Keep retired resources visible while they're still assigned.
+	for _, res := range assigned {
+		if !res.Active {
+			resources = append(resources, res)
+		}
+	}
+
+	return map[string]any{
+		"Inquiry":     inq,
+		"Resources":   resources,
+		"AssignedIDs": assignedIDs,
+		"Conflicts":   conflicts,
+		"KindLabels":  resourceKindLabels,
+		"Error":       errMsg,
+	}
+}
+
+func (a *Admin) renderInquirySchedule(w http.ResponseWriter, id int64, errMsg string) {
+	inq, err := a.store.GetInquiry(id)
+	if err != nil || inq == nil {
+		http.Error(w, "Inquiry not found", http.StatusNotFound)
+		return
+	}
+	if err := a.templates["admin-inquiry-detail"].ExecuteTemplate(w, "inquiry-schedule", a.inquiryScheduleData(inq, errMsg)); err != nil {
+		log.Printf("Error rendering inquiry schedule: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// slotAvailability derives public availability from the schedule. It holds
+// the active resources and every booking overlapping the slots it was
+// loaded for, so a page of trips costs two queries however many slots it
+// shows.
+type slotAvailability struct {
+	resources []db.Resource
+	bookings  []db.Booking
+	now       time.Time
+}
+
+// loadSlotAvailability reads the resources and the bookings spanning all of
+// the given slots. It returns nil when there is nothing to derive from, in
+// which case slots are shown as configured.
+func loadSlotAvailability(store *db.Store, slotLists ...[]data.DateSlot) *slotAvailability {
+	if store == nil {
+		return nil
+	}
+	now := time.Now()
+	var from, to time.Time
+	for _, slots := range slotLists {
+		for _, slot := range slots {
+			start, end, ok := data.ParseSlotDates(slot.Dates, now)
+			if !ok {
+				continue
+			}
+			if from.IsZero() || start.Before(from) {
+				from = start
+			}
+			if end.After(to) {
+				to = end
+			}
+		}
+	}
+	if from.IsZero() {
+		return nil
+	}
+	resources, err := store.ListResources(true)
+	if err != nil || len(resources) == 0 {
+		return nil
+	}
+	bookings, err := store.BookingsBetween(from, to)
+	if err != nil {
+		log.Printf("Error loading bookings for availability: %v", err)
+		return nil
+	}
+	return &slotAvailability{resources: resources, bookings: bookings, now: now}
+}
+
+// derive marks a trip's slots booked when any kind of resource serving the
+// trip has run out: every guide, or every boat, already committed for the
+// whole slot. Slots whose labels can't be read as a date range are left
+// untouched, as are trips with no resources configured.
+func (sa *slotAvailability) derive(slug string, slots []data.DateSlot) []data.DateSlot {
+	if sa == nil || len(slots) == 0 {
+		return slots
+	}
+	byKind := map[string][]db.Resource{}
+	for _, res := range sa.resources {
+		if res.Serves(slug) {
+			byKind[res.Kind] = append(byKind[res.Kind], res)
+		}
+	}
+	if len(byKind) == 0 {
+		return slots
+	}
+
+	out := make([]data.DateSlot, len(slots))
+	copy(out, slots)
+	for i, slot := range out {
+		if slot.Status == "booked" {
+			continue
+		}
+		start, end, ok := data.ParseSlotDates(slot.Dates, sa.now)
+		if !ok {
+			continue
+		}
+		for _, group := range byKind {
+			if allResourcesBusy(group, sa.bookings, start, end) {
+				out[i].Status = "booked"
+				out[i].Note = ""
+				break
+			}
+		}
+	}
+	return out
+}
+
+// allResourcesBusy reports whether each resource has a booking on every day of [start, end].
+func allResourcesBusy(resources []db.Resource, bookings []db.Booking, start, end time.Time) bool {
+	for _, res := range resources {
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			busy := false
+			for _, b := range bookings {
+				if b.Overlaps(day, day) && holdsResource(b, res.ID) {
+					busy = true
+					break
+				}
+			}
+			if !busy {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/sitemap"
+)
+
+// ReportsModTime returns when the newest report was published or edited, for
+// the lastmod of pages that list reports.
+func (p *Pages) ReportsModTime() time.Time {
+	var newest time.Time
+	for _, post := range p.reports(time.Now()) {
+		if post.Updated.After(newest) {
+			newest = post.Updated
+		}
+	}
+	return newest
+}
+
+// GalleryModTime returns when a gallery photo was last added or edited.
+func (p *Pages) GalleryModTime() time.Time {
+	if p.store == nil {
+		return time.Time{}
+	}
+	t, err := p.store.GalleryModTime()
+	if err != nil {
+		log.Printf("[sitemap] %v", err)
+	}
+	return t
+}
+
+// ReportURLs lists every published report for the sitemap.
+func (p *Pages) ReportURLs() ([]sitemap.URL, error) {
+	var urls []sitemap.URL
+	for _, post := range p.reports(time.Now()) {
+		urls = append(urls, sitemap.URL{Loc: post.URL(), LastMod: post.Updated, Priority: 0.6})
+	}
+	return urls, nil
+}
+
+// GearURLs lists the printable gear page of every trip that has one. They
+// change when the gear template or the trip content does, which ships with
+// the binary, so the template's modification time stands in for both.
+func (p *Pages) GearURLs(templateModTime func() time.Time) func() ([]sitemap.URL, error) {
+	return func() ([]sitemap.URL, error) {
+		lastMod := templateModTime()
+		var urls []sitemap.URL
+		for _, t := range allTrips {
+			if trip, ok := data.FindTrip(t.Slug); ok && len(trip.Gear) > 0 {
+				urls = append(urls, sitemap.URL{Loc: "/trips/" + t.Slug + "/gear/", LastMod: lastMod, Priority: 0.4})
+			}
+		}
+		return urls, nil
+	}
+}
+
+// Robots serves static/robots.txt from site with its Sitemap line pointing
+// at the configured site URL rather than whatever the file was written with.
+func Robots(site fs.FS, siteURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := site.Open("static/robots.txt")
+		if err != nil {
+			log.Printf("Error reading robots.txt: %v", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+
+		var b strings.Builder
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			if strings.HasPrefix(strings.ToLower(sc.Text()), "sitemap:") {
+				continue
+			}
+			b.WriteString(sc.Text() + "\n")
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "%sSitemap: %s/sitemap.xml\n", strings.TrimRight(b.String(), "\n")+"\n\n", siteURL)
+	}
+}
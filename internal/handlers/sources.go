@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+)
+
+// sourcePeriods are the report's date ranges in days; 0 is all time.
+var sourcePeriods = []int{30, 90, 365, 0}
+
+// SourcesReport shows inquiries, bookings and deposit revenue by where
+// clients came from: totals per source and medium, then per campaign.
+func (a *Admin) SourcesReport(w http.ResponseWriter, r *http.Request) {
+	days := 365
+	if n, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil {
+		for _, p := range sourcePeriods {
+			if n == p {
+				days = n
+			}
+		}
+	}
+	var since time.Time
+	if days > 0 {
+		since = time.Now().AddDate(0, 0, -days)
+	}
+
+	stats, err := a.store.SourceReport(since)
+	if err != nil {
+		log.Printf("Error loading source report: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	var bySource, campaigns []db.SourceStat
+	var total db.SourceStat
+	index := map[[2]string]int{}
+	for _, st := range stats {
+		key := [2]string{st.Source, st.Medium}
+		i, ok := index[key]
+		if !ok {
+			i = len(bySource)
+			index[key] = i
+			bySource = append(bySource, db.SourceStat{Source: st.Source, Medium: st.Medium})
+		}
+		addSourceStat(&bySource[i], st)
+		addSourceStat(&total, st)
+		if st.Campaign != "" {
+			campaigns = append(campaigns, st)
+		}
+	}
+	// Rows arrive ordered by inquiries per campaign; re-sort the merged ones.
+	for i := 1; i < len(bySource); i++ {
+		for j := i; j > 0 && bySource[j].Inquiries > bySource[j-1].Inquiries; j-- {
+			bySource[j], bySource[j-1] = bySource[j-1], bySource[j]
+		}
+	}
+
+	d := map[string]any{
+		"Meta":      data.PageMeta{Title: "Sources — MT Hunt & Fish Outfitters"},
+		"BySource":  bySource,
+		"Campaigns": campaigns,
+		"Total":     total,
+		"Days":      days,
+		"Periods":   sourcePeriods,
+		"ActiveNav": "sources",
+	}
+	if err := a.templates["admin-sources"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering source report: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func addSourceStat(dst *db.SourceStat, st db.SourceStat) {
+	dst.Inquiries += st.Inquiries
+	dst.Bookings += st.Bookings
+	dst.DepositCents += st.DepositCents
+}
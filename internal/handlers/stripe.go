@@ -3,7 +3,6 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"io"
 	"log"
 	"net/http"
@@ -11,6 +10,8 @@ import (
 
 	"github.com/firefly/packstring/internal/data"
 	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/metrics"
+	"github.com/firefly/packstring/internal/views"
 	"github.com/stripe/stripe-go/v81"
 	"github.com/stripe/stripe-go/v81/checkout/session"
 	"github.com/stripe/stripe-go/v81/webhook"
@@ -73,6 +74,7 @@ func (h *StripeHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	payload, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.Printf("[stripe] read body error: %v", err)
+		metrics.StripeWebhooks.Inc("unknown", "bad_request")
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
@@ -88,22 +90,26 @@ func (h *StripeHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		event, err = webhook.ConstructEvent(payload, r.Header.Get("Stripe-Signature"), endpointSecret)
 		if err != nil {
 			log.Printf("[stripe] signature verification failed: %v", err)
+			metrics.StripeWebhooks.Inc("unknown", "bad_signature")
 			http.Error(w, "Invalid signature", http.StatusBadRequest)
 			return
 		}
 	} else {
 		if err := json.Unmarshal(payload, &event); err != nil {
 			log.Printf("[stripe] unmarshal error: %v", err)
+			metrics.StripeWebhooks.Inc("unknown", "bad_request")
 			http.Error(w, "Bad request", http.StatusBadRequest)
 			return
 		}
 	}
 
+	outcome := "ok"
 	switch event.Type {
 	case "checkout.session.completed":
 		var cs stripe.CheckoutSession
 		if err := json.Unmarshal(event.Data.Raw, &cs); err != nil {
 			log.Printf("[stripe] unmarshal session: %v", err)
+			metrics.StripeWebhooks.Inc(string(event.Type), "bad_request")
 			http.Error(w, "Bad request", http.StatusBadRequest)
 			return
 		}
@@ -111,28 +117,37 @@ func (h *StripeHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 
 		if err := h.store.UpdatePaymentStatus(cs.ID, "paid", cs.PaymentIntent.ID); err != nil {
 			log.Printf("[stripe] update payment status error: %v", err)
+			metrics.DBErrors.Inc("update_payment")
+			outcome = "db_error"
 		}
 
 	case "checkout.session.expired":
 		var cs stripe.CheckoutSession
 		if err := json.Unmarshal(event.Data.Raw, &cs); err != nil {
 			log.Printf("[stripe] unmarshal session: %v", err)
+			outcome = "bad_request"
 			break
 		}
 		log.Printf("[stripe] checkout.session.expired: %s", cs.ID)
 		if err := h.store.UpdatePaymentStatus(cs.ID, "failed", ""); err != nil {
 			log.Printf("[stripe] update payment status error: %v", err)
+			metrics.DBErrors.Inc("update_payment")
+			outcome = "db_error"
 		}
 
 	default:
 		log.Printf("[stripe] unhandled event type: %s", event.Type)
+		metrics.StripeWebhooks.Inc("other", "ignored")
+		w.WriteHeader(http.StatusOK)
+		return
 	}
 
+	metrics.StripeWebhooks.Inc(string(event.Type), outcome)
 	w.WriteHeader(http.StatusOK)
 }
 
 // PaymentSuccess renders the public payment success page.
-func PaymentSuccess(templates map[string]*template.Template) http.HandlerFunc {
+func PaymentSuccess(templates views.Pages) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		d := map[string]any{
 			"Meta": data.PageMeta{Title: "Payment Received — MT Hunt & Fish Outfitters"},
@@ -145,7 +160,7 @@ func PaymentSuccess(templates map[string]*template.Template) http.HandlerFunc {
 }
 
 // PaymentCancel renders the public payment cancelled page.
-func PaymentCancel(templates map[string]*template.Template) http.HandlerFunc {
+func PaymentCancel(templates views.Pages) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		d := map[string]any{
 			"Meta": data.PageMeta{Title: "Payment Cancelled — MT Hunt & Fish Outfitters"},
@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/middleware"
+	"github.com/firefly/packstring/internal/pdf"
+	"github.com/firefly/packstring/internal/views"
+)
+
+// newToken returns a random 32-byte hex token for unguessable public links.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// tripCategory returns the catalog category ("Fishing", "Hunting", "Packages") for a trip slug.
+func tripCategory(slug string) string {
+	for _, t := range allTrips {
+		if t.Slug == slug {
+			return t.Category
+		}
+	}
+	return ""
+}
+
+// --- Admin ---
+
+// waiverRow pairs a waiver with its public signing URL for the inquiry page.
+type waiverRow struct {
+	db.Waiver
+	URL string
+}
+
+// inquiryWaiversData gathers what the inquiry-waivers partial needs.
+func (a *Admin) inquiryWaiversData(inq *db.Inquiry) map[string]any {
+	waivers, _ := a.store.WaiversByInquiry(inq.ID)
+	rows := make([]waiverRow, len(waivers))
+	signed := 0
+	for i, w := range waivers {
+		rows[i] = waiverRow{Waiver: w, URL: siteURL() + "/waivers/" + w.Token}
+		if w.Signed() {
+			signed++
+		}
+	}
+	return map[string]any{
+		"Inquiry":   inq,
+		"Waivers":   rows,
+		"Signed":    signed,
+		"Category":  tripCategory(inq.TripSlug),
+		"PartySize": inq.Headcount(),
+	}
+}
+
+// CreateWaivers generates signing links for an inquiry, one per party member,
+// using the current waiver version for the trip's category.
+func (a *Admin) CreateWaivers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid inquiry ID", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	inq, err := a.store.GetInquiry(id)
+	if err != nil || inq == nil {
+		http.Error(w, "Inquiry not found", http.StatusNotFound)
+		return
+	}
+
+	category := tripCategory(inq.TripSlug)
+	tmpl, err := a.store.LatestWaiverTemplate(category)
+	if err != nil || tmpl == nil {
+		w.Header().Set("HX-Trigger", `{"showToast": "No waiver set up for this trip type"}`)
+		a.renderInquiryWaivers(w, inq)
+		return
+	}
+
+	existing, _ := a.store.WaiversByInquiry(id)
+	if len(existing) >= db.MaxPartySize {
+		w.Header().Set("HX-Trigger", fmt.Sprintf(`{"showToast": "This party already has the most waivers allowed (%d)"}`, db.MaxPartySize))
+		a.renderInquiryWaivers(w, inq)
+		return
+	}
+	count, err := strconv.Atoi(r.FormValue("count"))
+	if err != nil || count < 1 {
+		count = inq.Headcount() - len(existing)
+	}
+	if count < 1 {
+		count = 1
+	}
+	if len(existing)+count > db.MaxPartySize {
+		count = db.MaxPartySize - len(existing)
+	}
+
+	for i := 0; i < count; i++ {
+		token, err := newToken()
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		waiver := &db.Waiver{InquiryID: id, TemplateID: tmpl.ID, Token: token, PartyIndex: len(existing) + i + 1}
+		if _, err := a.store.CreateWaiver(waiver); err != nil {
+			log.Printf("Error creating waiver: %v", err)
+			http.Error(w, "Failed to create waiver links", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("HX-Trigger", fmt.Sprintf(`{"showToast": "%d waiver link(s) created"}`, count))
+	a.renderInquiryWaivers(w, inq)
+}
+
+func (a *Admin) renderInquiryWaivers(w http.ResponseWriter, inq *db.Inquiry) {
+	if err := a.templates["admin-inquiry-detail"].ExecuteTemplate(w, "inquiry-waivers", a.inquiryWaiversData(inq)); err != nil {
+		log.Printf("Error rendering inquiry waivers: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// loadSignedWaiver fetches a waiver with its template and inquiry for admin views.
+func (a *Admin) loadSignedWaiver(w http.ResponseWriter, r *http.Request) (*db.Waiver, *db.WaiverTemplate, *db.Inquiry, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid waiver ID", http.StatusBadRequest)
+		return nil, nil, nil, false
+	}
+	waiver, err := a.store.GetWaiver(id)
+	if err != nil || waiver == nil {
+		http.Error(w, "Waiver not found", http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+	tmpl, err := a.store.GetWaiverTemplate(waiver.TemplateID)
+	if err != nil || tmpl == nil {
+		http.Error(w, "Waiver template not found", http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+	inq, err := a.store.GetInquiry(waiver.InquiryID)
+	if err != nil || inq == nil {
+		http.Error(w, "Inquiry not found", http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+	return waiver, tmpl, inq, true
+}
+
+// hashVerified recomputes the document hash of a signed waiver and compares it to the stored value.
+func hashVerified(waiver *db.Waiver, tmpl *db.WaiverTemplate) bool {
+	if !waiver.Signed() {
+		return false
+	}
+	return db.WaiverDocumentHash(tmpl, waiver.InquiryID, waiver.SignerName, *waiver.SignedAt) == waiver.DocumentHash
+}
+
+// WaiverView renders a single waiver with its signature record.
+func (a *Admin) WaiverView(w http.ResponseWriter, r *http.Request) {
+	waiver, tmpl, inq, ok := a.loadSignedWaiver(w, r)
+	if !ok {
+		return
+	}
+	d := map[string]any{
+		"Meta":      data.PageMeta{Title: fmt.Sprintf("Waiver — %s — MT Hunt & Fish Outfitters", inq.Name)},
+		"Waiver":    waiver,
+		"Template":  tmpl,
+		"Inquiry":   inq,
+		"Verified":  hashVerified(waiver, tmpl),
+		"ActiveNav": "inquiries",
+	}
+	if err := a.templates["admin-waiver"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering waiver: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// WaiverPDF downloads a signed waiver as a PDF.
+func (a *Admin) WaiverPDF(w http.ResponseWriter, r *http.Request) {
+	waiver, tmpl, inq, ok := a.loadSignedWaiver(w, r)
+	if !ok {
+		return
+	}
+	if !waiver.Signed() {
+		http.Error(w, "Waiver has not been signed yet", http.StatusConflict)
+		return
+	}
+
+	doc := pdf.New()
+	doc.Heading("MT Hunt & Fish Outfitters", 10)
+	doc.Heading(tmpl.Title, 16)
+	doc.Text(fmt.Sprintf("%s waiver, version %d", tmpl.Category, tmpl.Version), 9)
+	doc.Space(12)
+	for _, p := range tmpl.Paragraphs() {
+		doc.Text(p, 11)
+		doc.Space(8)
+	}
+	doc.Space(8)
+	doc.Rule()
+	doc.Label("Signature Record", 11)
+	doc.Space(4)
+	doc.Text("Signed by: "+waiver.SignerName, 10)
+	doc.Text("Signed at: "+waiver.SignedAt.UTC().Format("January 2, 2006 at 15:04:05 MST"), 10)
+	doc.Text("IP address: "+waiver.SignerIP, 10)
+	doc.Text(fmt.Sprintf("Booking: Inquiry #%d, %s (%s)", inq.ID, inq.Name, inq.TripName), 10)
+	doc.Text(fmt.Sprintf("Party member: %d", waiver.PartyIndex), 10)
+	doc.Text("Document SHA-256: "+waiver.DocumentHash, 9)
+
+	filename := fmt.Sprintf("waiver-%d-%d.pdf", inq.ID, waiver.PartyIndex)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Write(doc.Bytes())
+}
+
+// WaiverTemplatesPage shows the current waiver text per category with a form to publish a new version.
+func (a *Admin) WaiverTemplatesPage(w http.ResponseWriter, r *http.Request) {
+	all, err := a.store.ListWaiverTemplates()
+	if err != nil {
+		log.Printf("Error loading waiver templates: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	type categoryTemplates struct {
+		Category string
+		Current  *db.WaiverTemplate
+		Older    []db.WaiverTemplate
+	}
+	var groups []categoryTemplates
+	for _, c := range db.WaiverCategories {
+		g := categoryTemplates{Category: c}
+		for i := range all {
+			if all[i].Category != c {
+				continue
+			}
+			if g.Current == nil {
+				g.Current = &all[i]
+			} else {
+				g.Older = append(g.Older, all[i])
+			}
+		}
+		groups = append(groups, g)
+	}
+
+	d := map[string]any{
+		"Meta":      data.PageMeta{Title: "Waivers — MT Hunt & Fish Outfitters"},
+		"Groups":    groups,
+		"ActiveNav": "waivers",
+	}
+	if err := a.templates["admin-waivers"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering waiver templates: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// PublishWaiverTemplate saves edited waiver text as the next version for its category.
+func (a *Admin) PublishWaiverTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	_, err := a.store.PublishWaiverTemplate(
+		r.FormValue("category"),
+		strings.TrimSpace(r.FormValue("title")),
+		strings.TrimSpace(r.FormValue("body")),
+	)
+	if err != nil {
+		log.Printf("Error publishing waiver template: %v", err)
+		http.Error(w, "Failed to publish: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, "/admin/waivers/", http.StatusSeeOther)
+}
+
+// --- Public signing ---
+
+// Waivers serves the public signing pages behind per-guest links.
+type Waivers struct {
+	templates views.Pages
+	store     *db.Store
+}
+
+// NewWaivers creates the public waiver signing handler.
+func NewWaivers(templates views.Pages, store *db.Store) *Waivers {
+	return &Waivers{templates: templates, store: store}
+}
+
+// load resolves a signing token to its waiver, template, and inquiry.
+func (h *Waivers) load(w http.ResponseWriter, r *http.Request) (*db.Waiver, *db.WaiverTemplate, *db.Inquiry, bool) {
+	waiver, err := h.store.GetWaiverByToken(r.PathValue("token"))
+	if err != nil {
+		log.Printf("[waivers] lookup error: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	if waiver == nil {
+		http.Error(w, "This waiver link isn't valid. Check the link or call Forrest at (406) 459-5352.", http.StatusNotFound)
+		return nil, nil, nil, false
+	}
+	tmpl, err := h.store.GetWaiverTemplate(waiver.TemplateID)
+	if err != nil || tmpl == nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	inq, err := h.store.GetInquiry(waiver.InquiryID)
+	if err != nil || inq == nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, nil, nil, false
+	}
+	return waiver, tmpl, inq, true
+}
+
+func (h *Waivers) render(w http.ResponseWriter, waiver *db.Waiver, tmpl *db.WaiverTemplate, inq *db.Inquiry, errMsg string) {
+	d := map[string]any{
+		"Meta":     data.PageMeta{Title: tmpl.Title + " — MT Hunt & Fish Outfitters"},
+		"Waiver":   waiver,
+		"Template": tmpl,
+		"TripName": inq.TripName,
+		"Error":    errMsg,
+	}
+	if err := h.templates["waiver"].ExecuteTemplate(w, "base.html", d); err != nil {
+		log.Printf("Error rendering waiver page: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// SignPage shows the waiver text and signature form, or the signed receipt.
+func (h *Waivers) SignPage(w http.ResponseWriter, r *http.Request) {
+	waiver, tmpl, inq, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	h.render(w, waiver, tmpl, inq, "")
+}
+
+// Sign records the guest's typed name as their signature.
+func (h *Waivers) Sign(w http.ResponseWriter, r *http.Request) {
+	waiver, tmpl, inq, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	if waiver.Signed() {
+		h.render(w, waiver, tmpl, inq, "")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.Join(strings.Fields(r.FormValue("signer_name")), " ")
+	if name == "" || r.FormValue("agree") != "on" {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.render(w, waiver, tmpl, inq, "Type your full name and check the box to sign.")
+		return
+	}
+
+	signedAt := time.Now().UTC().Truncate(time.Second)
+	hash := db.WaiverDocumentHash(tmpl, waiver.InquiryID, name, signedAt)
+	err := h.store.SignWaiver(waiver.ID, name, middleware.GetClientIP(r), hash, signedAt)
+	switch {
+	case errors.Is(err, db.ErrWaiverSigned):
+		// Signed from another tab or device in the meantime; show that one.
+	case err != nil:
+		log.Printf("[waivers] sign error: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		h.render(w, waiver, tmpl, inq, "Your signature couldn't be saved. Please try again in a minute, or call Forrest at (406) 459-5352.")
+		return
+	default:
+		log.Printf("[waivers] waiver #%d for inquiry #%d signed by %s", waiver.ID, waiver.InquiryID, name)
+	}
+
+	http.Redirect(w, r, "/waivers/"+waiver.Token, http.StatusSeeOther)
+}
@@ -0,0 +1,179 @@
+// Package imaging decodes uploaded photos and produces the resized variants
+// the site serves, replacing the ImageMagick and cwebp steps in
+// scripts/optimize-images.sh. It uses only the standard library.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // register decoders
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+)
+
+// MaxPixels bounds the size of an image Decode will accept, so a small file
+// that claims enormous dimensions can't exhaust memory.
+const MaxPixels = 50_000_000
+
+// Decode reads a JPEG, PNG or GIF, applying any EXIF orientation so photos
+// from phones come out upright. It returns the image and its format name.
+func Decode(r io.Reader) (image.Image, string, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("read image: %w", err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, "", fmt.Errorf("unrecognized image: %w", err)
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", fmt.Errorf("image is too large (%d×%d)", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, "", fmt.Errorf("decode %s: %w", format, err)
+	}
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(src))
+	}
+	return img, format, nil
+}
+
+// Resize scales img to the given width, keeping its aspect ratio. Images
+// already that narrow are copied at their own size rather than enlarged,
+// matching the "-resize 800x>" behaviour of the old script.
+func Resize(img image.Image, width int) *image.RGBA {
+	b := img.Bounds()
+	if width >= b.Dx() {
+		m := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(m, m.Bounds(), img, b.Min, draw.Src)
+		return m
+	}
+	height := max(1, int(math.Round(float64(b.Dy())*float64(width)/float64(b.Dx()))))
+	return scale(toRGBA(img), width, height)
+}
+
+// Fill scales and center-crops img to exactly width×height, like the
+// gallery thumbnails' "-resize 400x300^ -gravity center -extent 400x300".
+func Fill(img image.Image, width, height int) *image.RGBA {
+	b := img.Bounds()
+	// Crop the largest centered region with the target aspect ratio, then
+	// scale that down.
+	cw, ch := b.Dx(), b.Dy()
+	if cw*height > ch*width {
+		cw = max(1, ch*width/height)
+	} else {
+		ch = max(1, cw*height/width)
+	}
+	x0 := b.Min.X + (b.Dx()-cw)/2
+	y0 := b.Min.Y + (b.Dy()-ch)/2
+	crop := image.NewRGBA(image.Rect(0, 0, cw, ch))
+	draw.Draw(crop, crop.Bounds(), img, image.Pt(x0, y0), draw.Src)
+	if cw <= width && ch <= height {
+		return crop
+	}
+	return scale(crop, width, height)
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if m, ok := img.(*image.RGBA); ok && m.Bounds().Min == (image.Point{}) {
+		return m
+	}
+	b := img.Bounds()
+	m := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(m, m.Bounds(), img, b.Min, draw.Src)
+	return m
+}
+
+// scale resamples src to w×h by area averaging: every destination pixel is
+// the coverage-weighted mean of the source pixels under it. That is the
+// right filter for the downscaling the site does and needs no tuning. Work
+// is done on premultiplied values so transparent edges don't darken.
+func scale(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+
+	// Horizontal pass into a float buffer, then vertical into the result.
+	xw := weights(sw, w)
+	tmp := make([]float32, w*sh*4)
+	for y := 0; y < sh; y++ {
+		row := src.Pix[y*src.Stride:]
+		for x, ws := range xw {
+			var r, g, b, a float32
+			for _, t := range ws.taps {
+				p := row[t.i*4:]
+				r += float32(p[0]) * t.w
+				g += float32(p[1]) * t.w
+				b += float32(p[2]) * t.w
+				a += float32(p[3]) * t.w
+			}
+			o := (y*w + x) * 4
+			tmp[o], tmp[o+1], tmp[o+2], tmp[o+3] = r, g, b, a
+		}
+	}
+
+	yw := weights(sh, h)
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y, ws := range yw {
+		out := dst.Pix[y*dst.Stride:]
+		for x := 0; x < w; x++ {
+			var r, g, b, a float32
+			for _, t := range ws.taps {
+				o := (t.i*w + x) * 4
+				r += tmp[o] * t.w
+				g += tmp[o+1] * t.w
+				b += tmp[o+2] * t.w
+				a += tmp[o+3] * t.w
+			}
+			p := out[x*4:]
+			p[0], p[1], p[2], p[3] = clamp8(r), clamp8(g), clamp8(b), clamp8(a)
+		}
+	}
+	return dst
+}
+
+type tap struct {
+	i int
+	w float32
+}
+
+type tapSet struct{ taps []tap }
+
+// weights returns, for each of n output samples, the source samples it
+// covers and how much of each, normalized to sum to 1.
+func weights(srcN, n int) []tapSet {
+	ratio := float64(srcN) / float64(n)
+	out := make([]tapSet, n)
+	for i := range out {
+		lo, hi := float64(i)*ratio, float64(i+1)*ratio
+		var taps []tap
+		var sum float64
+		for s := int(lo); s < srcN && float64(s) < hi; s++ {
+			cover := math.Min(hi, float64(s+1)) - math.Max(lo, float64(s))
+			if cover <= 0 {
+				continue
+			}
+			taps = append(taps, tap{i: s, w: float32(cover)})
+			sum += cover
+		}
+		for j := range taps {
+			taps[j].w /= float32(sum)
+		}
+		out[i] = tapSet{taps}
+	}
+	return out
+}
+
+func clamp8(v float32) uint8 {
+	v += 0.5
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v)
+}
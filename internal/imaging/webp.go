@@ -0,0 +1,567 @@
+package imaging
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math/bits"
+	"slices"
+)
+
+// EncodeWebP writes img as a lossless WebP (VP8L). Lossless files are much
+// larger than cwebp's lossy output, so photos are better sent as JPEG.
+//
+// The encoder uses the subtract-green and predictor transforms, run-length
+// backward references, and per-image prefix codes; it skips the color cache
+// and the meta prefix codes, which add a lot of code for a few percent.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > 1<<14 || height > 1<<14 {
+		return fmt.Errorf("webp: invalid dimensions %d×%d", width, height)
+	}
+
+	argb := make([]uint32, width*height)
+	opaque := true
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			argb[y*width+x] = uint32(c.A)<<24 | uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+			opaque = opaque && c.A == 0xff
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8) // VP8L signature
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if opaque {
+		bw.write(0, 1)
+	} else {
+		bw.write(1, 1)
+	}
+	bw.write(0, 3) // version
+
+	// Transforms are listed in the order the encoder applies them; the
+	// decoder undoes them in reverse.
+	subtractGreen(argb)
+	bw.write(1, 1)
+	bw.write(2, 2) // SUBTRACT_GREEN
+
+	const predictorBits = 4
+	modes := predict(argb, width, height, predictorBits)
+	bw.write(1, 1)
+	bw.write(0, 2) // PREDICTOR
+	bw.write(predictorBits-2, 3)
+	writeImage(bw, modes, ceilShift(width, predictorBits), false)
+
+	bw.write(0, 1) // no more transforms
+	writeImage(bw, argb, width, true)
+	data := bw.bytes()
+
+	// RIFF container with a single VP8L chunk, padded to an even size.
+	pad := len(data) & 1
+	hdr := make([]byte, 20)
+	copy(hdr[0:], "RIFF")
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(4+8+len(data)+pad))
+	copy(hdr[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(hdr[16:], uint32(len(data)))
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if pad == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+func ceilShift(n int, bits uint) int {
+	return (n + 1<<bits - 1) >> bits
+}
+
+// subtractGreen replaces red and blue with their difference from green.
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		g := (p >> 8) & 0xff
+		r := ((p >> 16) - g) & 0xff
+		b := (p - g) & 0xff
+		argb[i] = p&0xff00ff00 | r<<16 | b
+	}
+}
+
+// Predictor modes the encoder tries. They are the simple ones from the
+// spec; the remaining modes rarely win on photos.
+var predictorModes = []int{1, 2, 7, 12}
+
+// predict replaces argb with prediction residuals, choosing one mode per
+// (1<<sizeBits)² block, and returns the block modes as a sub-image with the
+// mode in the green channel.
+func predict(argb []uint32, width, height int, sizeBits uint) []uint32 {
+	bw, bh := ceilShift(width, sizeBits), ceilShift(height, sizeBits)
+	block := 1 << sizeBits
+	modes := make([]uint32, bw*bh)
+	orig := slices.Clone(argb)
+
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			best, bestCost := predictorModes[0], -1
+			for _, mode := range predictorModes {
+				cost := 0
+				for y := by * block; y < min(height, (by+1)*block); y++ {
+					for x := bx * block; x < min(width, (bx+1)*block); x++ {
+						cost += residualCost(orig[y*width+x], predictAt(orig, width, x, y, mode))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[by*bw+bx] = 0xff000000 | uint32(best)<<8
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mode := int(modes[(y>>sizeBits)*bw+(x>>sizeBits)]>>8) & 0xf
+			argb[y*width+x] = subPixels(orig[y*width+x], predictAt(orig, width, x, y, mode))
+		}
+	}
+	return modes
+}
+
+// predictAt returns the predicted pixel at (x, y). The first pixel, the top
+// row and the left column use fixed predictors regardless of mode.
+func predictAt(p []uint32, width, x, y, mode int) uint32 {
+	i := y*width + x
+	switch {
+	case x == 0 && y == 0:
+		return 0xff000000
+	case y == 0:
+		return p[i-1]
+	case x == 0:
+		return p[i-width]
+	}
+	l, t, tl := p[i-1], p[i-width], p[i-width-1]
+	switch mode {
+	case 1:
+		return l
+	case 2:
+		return t
+	case 7:
+		return average2(l, t)
+	case 12:
+		return clampAddSubtractFull(l, t, tl)
+	}
+	return 0xff000000
+}
+
+func average2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+func clampAddSubtractFull(a, b, c uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		v := int(a>>shift&0xff) + int(b>>shift&0xff) - int(c>>shift&0xff)
+		out |= uint32(max(0, min(255, v))) << shift
+	}
+	return out
+}
+
+// subPixels subtracts per channel, modulo 256.
+func subPixels(a, b uint32) uint32 {
+	ag := (a | 0x00ff00ff) - (b & 0xff00ff00)
+	rb := (a | 0xff00ff00) - (b & 0x00ff00ff)
+	return ag&0xff00ff00 | rb&0x00ff00ff
+}
+
+// residualCost estimates how expensive a residual is to code: small signed
+// differences are cheap.
+func residualCost(pixel, pred uint32) int {
+	d := subPixels(pixel, pred)
+	cost := 0
+	for shift := 0; shift < 32; shift += 8 {
+		v := int8(d >> shift)
+		if v < 0 {
+			v = -v
+		}
+		cost += int(uint8(v))
+	}
+	return cost
+}
+
+// Alphabet sizes of the five prefix codes in a group: green plus length
+// prefixes, red, blue, alpha, distance prefixes.
+const (
+	numLiterals      = 256
+	numLengthCodes   = 24
+	numDistanceCodes = 40
+	maxBackrefLength = 4096
+	minBackrefLength = 3
+)
+
+// symbol is one coded element of an image: a literal pixel or a backward
+// reference copying length pixels from dist pixels back.
+type symbol struct {
+	argb   uint32
+	length int // 0 for a literal
+	dist   int
+}
+
+// backrefs turns pixels into literals and run-length references, either
+// repeating the previous pixel or copying the row above.
+func backrefs(argb []uint32, width int) []symbol {
+	var out []symbol
+	for i := 0; i < len(argb); {
+		bestLen, bestDist := 0, 0
+		for _, dist := range []int{1, width} {
+			if dist > i {
+				continue
+			}
+			n := 0
+			for i+n < len(argb) && n < maxBackrefLength && argb[i+n] == argb[i+n-dist] {
+				n++
+			}
+			if n > bestLen {
+				bestLen, bestDist = n, dist
+			}
+		}
+		if bestLen >= minBackrefLength {
+			out = append(out, symbol{length: bestLen, dist: bestDist})
+			i += bestLen
+			continue
+		}
+		out = append(out, symbol{argb: argb[i]})
+		i++
+	}
+	return out
+}
+
+// prefixEncode splits a length or distance value into its prefix code and
+// extra bits.
+func prefixEncode(v int) (code int, nbits uint, extra uint32) {
+	if v <= 4 {
+		return v - 1, 0, 0
+	}
+	d := v - 1
+	h := bits.Len(uint(d)) - 1
+	second := (d >> (h - 1)) & 1
+	return 2*h + second, uint(h - 1), uint32(d & (1<<(h-1) - 1))
+}
+
+// writeImage entropy-codes an image. The main image carries a meta prefix
+// flag; transform sub-images do not.
+func writeImage(bw *bitWriter, argb []uint32, width int, main bool) {
+	syms := backrefs(argb, width)
+
+	hist := [5][]int{
+		make([]int, numLiterals+numLengthCodes),
+		make([]int, numLiterals),
+		make([]int, numLiterals),
+		make([]int, numLiterals),
+		make([]int, numDistanceCodes),
+	}
+	for _, s := range syms {
+		if s.length == 0 {
+			hist[0][s.argb>>8&0xff]++
+			hist[1][s.argb>>16&0xff]++
+			hist[2][s.argb&0xff]++
+			hist[3][s.argb>>24]++
+			continue
+		}
+		lc, _, _ := prefixEncode(s.length)
+		dc, _, _ := prefixEncode(s.dist + 120) // linear distances follow the 120 plane codes
+		hist[0][numLiterals+lc]++
+		hist[4][dc]++
+	}
+
+	bw.write(0, 1) // no color cache
+	if main {
+		bw.write(0, 1) // no meta prefix codes
+	}
+	var codes [5][]huffCode
+	for i, h := range hist {
+		codes[i] = writePrefixCode(bw, h)
+	}
+
+	for _, s := range syms {
+		if s.length == 0 {
+			codes[0][s.argb>>8&0xff].write(bw)
+			codes[1][s.argb>>16&0xff].write(bw)
+			codes[2][s.argb&0xff].write(bw)
+			codes[3][s.argb>>24].write(bw)
+			continue
+		}
+		lc, ln, lx := prefixEncode(s.length)
+		codes[0][numLiterals+lc].write(bw)
+		bw.write(lx, ln)
+		dc, dn, dx := prefixEncode(s.dist + 120)
+		codes[4][dc].write(bw)
+		bw.write(dx, dn)
+	}
+}
+
+// huffCode is a symbol's canonical prefix code, stored bit-reversed because
+// VP8L reads codes starting from the least significant bit.
+type huffCode struct {
+	bits uint32
+	len  uint
+}
+
+func (c huffCode) write(bw *bitWriter) { bw.write(c.bits, c.len) }
+
+// codeLengthOrder is the order code-length code lengths are transmitted in.
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// writePrefixCode writes the prefix code for a histogram and returns the
+// code for each symbol. An alphabet with at most one used symbol below 256
+// gets a "simple" code, which takes no bits per symbol.
+func writePrefixCode(bw *bitWriter, hist []int) []huffCode {
+	codes := make([]huffCode, len(hist))
+	used := 0
+	last := 0
+	for s, n := range hist {
+		if n > 0 {
+			used++
+			last = s
+		}
+	}
+	if used <= 1 && last < 256 {
+		bw.write(1, 1) // simple code
+		bw.write(0, 1) // one symbol
+		if last <= 1 {
+			bw.write(0, 1)
+			bw.write(uint32(last), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(last), 8)
+		}
+		return codes
+	}
+	if used == 1 {
+		// A normal code needs two symbols; give the unused one a token count.
+		hist = slices.Clone(hist)
+		hist[(last+1)%len(hist)] = 1
+	}
+
+	lengths := huffmanLengths(hist, 15)
+	assignCodes(lengths, codes)
+
+	tokens := codeLengthTokens(lengths)
+	tokHist := make([]int, 19)
+	for _, t := range tokens {
+		tokHist[t.code]++
+	}
+	if n := countUsed(tokHist); n == 1 {
+		for i := range tokHist {
+			if tokHist[i] == 0 {
+				tokHist[i] = 1
+				break
+			}
+		}
+	}
+	tokLengths := huffmanLengths(tokHist, 7)
+	tokCodes := make([]huffCode, 19)
+	assignCodes(tokLengths, tokCodes)
+
+	num := 19
+	for num > 4 && tokLengths[codeLengthOrder[num-1]] == 0 {
+		num--
+	}
+	bw.write(0, 1) // normal code
+	bw.write(uint32(num-4), 4)
+	for i := 0; i < num; i++ {
+		bw.write(uint32(tokLengths[codeLengthOrder[i]]), 3)
+	}
+	bw.write(0, 1) // code lengths cover the whole alphabet
+	for _, t := range tokens {
+		tokCodes[t.code].write(bw)
+		bw.write(t.extra, t.nbits)
+	}
+	return codes
+}
+
+func countUsed(hist []int) int {
+	n := 0
+	for _, v := range hist {
+		if v > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+type lengthToken struct {
+	code  int
+	extra uint32
+	nbits uint
+}
+
+// codeLengthTokens run-length codes a list of code lengths: 16 repeats the
+// previous non-zero length 3–6 times, 17 and 18 emit runs of zeros.
+func codeLengthTokens(lengths []uint8) []lengthToken {
+	var out []lengthToken
+	for i := 0; i < len(lengths); {
+		v := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == v {
+			run++
+		}
+		i += run
+		if v == 0 {
+			for run > 0 {
+				switch {
+				case run >= 11:
+					n := min(run, 138)
+					out = append(out, lengthToken{18, uint32(n - 11), 7})
+					run -= n
+				case run >= 3:
+					out = append(out, lengthToken{17, uint32(run - 3), 3})
+					run = 0
+				default:
+					out = append(out, lengthToken{code: 0})
+					run--
+				}
+			}
+			continue
+		}
+		out = append(out, lengthToken{code: int(v)})
+		run--
+		for run >= 3 {
+			n := min(run, 6)
+			out = append(out, lengthToken{16, uint32(n - 3), 2})
+			run -= n
+		}
+		for ; run > 0; run-- {
+			out = append(out, lengthToken{code: int(v)})
+		}
+	}
+	return out
+}
+
+// huffmanLengths builds code lengths for a histogram, limited to maxLen by
+// flattening small counts and rebuilding until the tree is shallow enough.
+func huffmanLengths(hist []int, maxLen int) []uint8 {
+	lengths := make([]uint8, len(hist))
+	for floor := 1; ; floor *= 2 {
+		counts := make([]int, len(hist))
+		for i, n := range hist {
+			if n > 0 {
+				counts[i] = max(n, floor)
+			}
+		}
+		if buildLengths(counts, lengths) <= maxLen {
+			return lengths
+		}
+	}
+}
+
+type huffNode struct {
+	count       int
+	symbol      int // -1 for internal nodes
+	left, right *huffNode
+}
+
+type nodeHeap []*huffNode
+
+func (h nodeHeap) Len() int { return len(h) }
+func (h nodeHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count < h[j].count
+	}
+	return h[i].symbol < h[j].symbol
+}
+func (h nodeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *nodeHeap) Push(x any)   { *h = append(*h, x.(*huffNode)) }
+func (h *nodeHeap) Pop() any {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// buildLengths fills lengths from a Huffman tree over counts and returns the
+// deepest length. Needs at least two non-zero counts.
+func buildLengths(counts []int, lengths []uint8) int {
+	h := &nodeHeap{}
+	for s, n := range counts {
+		lengths[s] = 0
+		if n > 0 {
+			*h = append(*h, &huffNode{count: n, symbol: s})
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 1 {
+		a := heap.Pop(h).(*huffNode)
+		b := heap.Pop(h).(*huffNode)
+		heap.Push(h, &huffNode{count: a.count + b.count, symbol: -1, left: a, right: b})
+	}
+	deepest := 0
+	var walk func(n *huffNode, depth int)
+	walk = func(n *huffNode, depth int) {
+		if n.symbol >= 0 {
+			lengths[n.symbol] = uint8(depth)
+			deepest = max(deepest, depth)
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk((*h)[0], 0)
+	return deepest
+}
+
+// assignCodes gives each symbol its canonical code, as in DEFLATE.
+func assignCodes(lengths []uint8, codes []huffCode) {
+	var count [16]uint32
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [16]uint32
+	code := uint32(0)
+	for l := 1; l < 16; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c := next[l]
+		next[l]++
+		codes[s] = huffCode{bits: bits.Reverse32(c) >> (32 - uint(l)), len: uint(l)}
+	}
+}
+
+// bitWriter packs values least significant bit first, as VP8L expects.
+type bitWriter struct {
+	buf []byte
+	acc uint64
+	n   uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v) << w.n
+	w.n += n
+	for w.n >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.n -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.n > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.n = 0, 0
+	}
+	return w.buf
+}
@@ -0,0 +1,106 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// TestWebPRoundTrip encodes images and decodes them with x/image/webp, which
+// must give back every pixel exactly: the encoder is lossless.
+func TestWebPRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	fills := map[string]func(x, y int) color.NRGBA{
+		"noise with alpha": func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256))}
+		},
+		"opaque noise": func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 0xff}
+		},
+		"gradient fading out": func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x * 7), uint8(y * 5), uint8(x + y), uint8(255 - x*3)}
+		},
+		"flat with runs": func(x, y int) color.NRGBA {
+			if (x/4+y/3)%2 == 0 {
+				return color.NRGBA{0x2e, 0x4a, 0x3a, 0xff}
+			}
+			return color.NRGBA{0xc8, 0x7a, 0x3c, 0x80}
+		},
+		"fully transparent": func(x, y int) color.NRGBA {
+			return color.NRGBA{}
+		},
+	}
+	sizes := [][2]int{{1, 1}, {3, 5}, {17, 9}, {33, 31}, {64, 64}, {257, 3}}
+
+	for name, fill := range fills {
+		for _, size := range sizes {
+			w, h := size[0], size[1]
+			t.Run(fmt.Sprintf("%s %dx%d", name, w, h), func(t *testing.T) {
+				src := image.NewNRGBA(image.Rect(0, 0, w, h))
+				for y := 0; y < h; y++ {
+					for x := 0; x < w; x++ {
+						src.SetNRGBA(x, y, fill(x, y))
+					}
+				}
+				var buf bytes.Buffer
+				if err := EncodeWebP(&buf, src); err != nil {
+					t.Fatalf("EncodeWebP: %v", err)
+				}
+				got, err := webp.Decode(&buf)
+				if err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if got.Bounds() != src.Bounds() {
+					t.Fatalf("bounds = %v, want %v", got.Bounds(), src.Bounds())
+				}
+				for y := 0; y < h; y++ {
+					for x := 0; x < w; x++ {
+						want := src.NRGBAAt(x, y)
+						if c := color.NRGBAModel.Convert(got.At(x, y)).(color.NRGBA); c != want {
+							t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, c, want)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestWebPRoundTripRGBA checks the premultiplied images the resizer passes
+// in come back as the same colors.
+func TestWebPRoundTripRGBA(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 19, 7))
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 19; x++ {
+			a := uint8(x * 13)
+			src.SetRGBA(x, y, color.RGBA{a / 2, a / 3, a, a})
+		}
+	}
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, src); err != nil {
+		t.Fatalf("EncodeWebP: %v", err)
+	}
+	got, err := webp.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 19; x++ {
+			want := color.NRGBAModel.Convert(src.At(x, y)).(color.NRGBA)
+			if c := color.NRGBAModel.Convert(got.At(x, y)).(color.NRGBA); c != want {
+				t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, c, want)
+			}
+		}
+	}
+}
+
+func TestWebPRejectsEmpty(t *testing.T) {
+	if err := EncodeWebP(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, 0, 4))); err == nil {
+		t.Error("encoded an image with no width")
+	}
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Monday, 2 March 2026.
+	from := time.Date(2026, 3, 2, 10, 7, 30, 0, time.UTC)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", at(3, 2, 10, 8)},
+		{"*/15 * * * *", at(3, 2, 10, 15)},
+		{"0 * * * *", at(3, 2, 11, 0)},
+		{"30 9 * * *", at(3, 3, 9, 30)},
+		{"5/20 * * * *", at(3, 2, 10, 25)},
+		{"0-30/10 10 * * *", at(3, 2, 10, 10)},
+		{"0 8-10 * * *", at(3, 3, 8, 0)},
+		{"0 9,17 * * *", at(3, 2, 17, 0)},
+		{"0 0 1 * *", at(4, 1, 0, 0)},
+		{"0 0 * 12 *", at(12, 1, 0, 0)},
+		{"59 23 31 12 *", at(12, 31, 23, 59)},
+		{"0 0 * * 0", at(3, 8, 0, 0)},
+		{"0 0 * * 1-5", at(3, 3, 0, 0)},
+
+		// Both day fields restricted: either one matching is enough.
+		{"0 0 13 * 5", at(3, 6, 0, 0)},
+		{"0 0 1-7 * 1", at(3, 3, 0, 0)},
+		// A stepped * is still a star, so both must match: an odd day that
+		// is a Monday.
+		{"0 0 */2 * 1", at(3, 9, 0, 0)},
+		{"0 0 1 * */2", at(8, 1, 0, 0)}, // the first 1st on a Sun, Tue, Thu or Sat
+
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+		{"0 0 31 4 *", time.Time{}},
+
+		{"@hourly", at(3, 2, 11, 0)},
+		{"@daily", at(3, 3, 0, 0)},
+		{"@midnight", at(3, 3, 0, 0)},
+		{"@weekly", at(3, 8, 0, 0)},
+		{"@monthly", at(4, 1, 0, 0)},
+		{"@every 90m", from.Add(90 * time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule: %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextIsStrictlyAfter(t *testing.T) {
+	s, err := ParseSchedule("0 8 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	if got, want := s.Next(run), run.AddDate(0, 0, 1); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", run, got, want)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@yearly",
+		"@every 10ms",
+		"@every soon",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package jobs runs periodic background work inside the server process on
+// cron-like schedules, recording every run in SQLite.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/firefly/packstring/internal/db"
+)
+
+// ErrRunning is returned by RunNow when the job is already in progress.
+var ErrRunning = errors.New("job is already running")
+
+// Func is the work a job does. It should return promptly once ctx is done.
+type Func func(ctx context.Context) error
+
+type job struct {
+	name     string
+	spec     string
+	schedule Schedule
+	fn       Func
+
+	mu      sync.Mutex // guards running and next
+	running bool       // set for the duration of a run so runs never overlap
+	next    time.Time
+}
+
+// Scheduler runs registered jobs on their schedules.
+type Scheduler struct {
+	store *db.Store
+	mu    sync.Mutex
+	jobs  []*job
+	ctx   context.Context
+	wg    sync.WaitGroup
+}
+
+// New creates a scheduler that records run history in the store.
+func New(store *db.Store) *Scheduler {
+	return &Scheduler{store: store}
+}
+
+// Add registers a job. It must be called before Start.
+func (s *Scheduler) Add(name, spec string, fn Func) error {
+	sched, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.name == name {
+			return fmt.Errorf("job %s already registered", name)
+		}
+	}
+	s.jobs = append(s.jobs, &job{name: name, spec: spec, schedule: sched, fn: fn})
+	return nil
+}
+
+// Start launches every job's timer loop. Jobs stop being scheduled when ctx
+// is cancelled; use Wait to block until in-flight runs finish.
+func (s *Scheduler) Start(ctx context.Context) {
+	if err := s.store.InterruptJobRuns(); err != nil {
+		log.Printf("[jobs] %v", err)
+	}
+	s.mu.Lock()
+	s.ctx = ctx
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+	log.Printf("[jobs] scheduler started with %d job(s)", len(jobs))
+}
+
+// Wait blocks until all job loops and in-flight runs have returned.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("[jobs] %s: schedule %q never fires", j.name, j.spec)
+			return
+		}
+		j.mu.Lock()
+		j.next = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if err := s.run(ctx, j); errors.Is(err, ErrRunning) {
+				log.Printf("[jobs] %s: previous run still in progress, skipping", j.name)
+			}
+		}
+	}
+}
+
+// RunNow runs a job immediately in the background, outside its schedule.
+func (s *Scheduler) RunNow(name string) error {
+	j := s.find(name)
+	if j == nil {
+		return fmt.Errorf("no job named %s", name)
+	}
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	j.mu.Lock()
+	busy := j.running
+	j.mu.Unlock()
+	if busy {
+		return ErrRunning
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx, j)
+	}()
+	return nil
+}
+
+// run executes one run of a job unless one is already in progress.
+func (s *Scheduler) run(ctx context.Context, j *job) (err error) {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		return ErrRunning
+	}
+	j.running = true
+	j.mu.Unlock()
+	defer func() {
+		j.mu.Lock()
+		j.running = false
+		j.mu.Unlock()
+	}()
+
+	started := time.Now()
+	id, dbErr := s.store.StartJobRun(j.name, started)
+	if dbErr != nil {
+		log.Printf("[jobs] %v", dbErr)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+		elapsed := time.Since(started)
+		if err != nil {
+			log.Printf("[jobs] %s failed after %s: %v", j.name, elapsed.Round(time.Millisecond), err)
+		}
+		if dbErr == nil {
+			if ferr := s.store.FinishJobRun(id, time.Now(), elapsed, err); ferr != nil {
+				log.Printf("[jobs] %v", ferr)
+			}
+		}
+	}()
+
+	return j.fn(ctx)
+}
+
+func (s *Scheduler) find(name string) *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+// Status describes a job for the admin page.
+type Status struct {
+	Name    string
+	Spec    string
+	Running bool
+	Next    time.Time
+	Last    *db.JobRun
+	Recent  []db.JobRun
+}
+
+// Statuses returns every job with its recent run history, sorted by name.
+func (s *Scheduler) Statuses(historyLimit int) ([]Status, error) {
+	s.mu.Lock()
+	jobs := append([]*job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	out := make([]Status, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		st := Status{Name: j.name, Spec: j.spec, Running: j.running, Next: j.next}
+		j.mu.Unlock()
+		if st.Next.IsZero() {
+			st.Next = j.schedule.Next(time.Now())
+		}
+
+		runs, err := s.store.RecentJobRuns(j.name, historyLimit)
+		if err != nil {
+			return nil, err
+		}
+		st.Recent = runs
+		if len(runs) > 0 {
+			st.Last = &runs[0]
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Name < out[b].Name })
+	return out, nil
+}
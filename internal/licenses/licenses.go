@@ -0,0 +1,306 @@
+// Package licenses tracks the Montana hunting licenses and tags each hunter
+// on a booked hunt needs, and emails reminders as deadlines approach.
+package licenses
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/mail"
+	"github.com/firefly/packstring/internal/metrics"
+)
+
+// Entry is one required license for one hunter, with what they've submitted.
+type Entry struct {
+	data.LicenseRequirement
+	LicenseNumber string
+	TagNumber     string
+	DueBy         *time.Time // the drawing deadline that applies to this hunt, if any
+}
+
+// Missing reports whether the hunter hasn't submitted a license number yet.
+func (e Entry) Missing() bool {
+	return e.LicenseNumber == ""
+}
+
+// Hunter is one member of the hunting party.
+type Hunter struct {
+	Position        int
+	Name            string
+	ManifestLicense string // license number the client gave on the guest manifest, if any
+	Entries         []Entry
+}
+
+// Status summarizes license progress for a booked hunt.
+type Status struct {
+	Inquiry      db.Inquiry
+	Hunters      []Hunter
+	Missing      int        // entries without a license number
+	NextDeadline *time.Time // soonest drawing deadline still open for a missing entry
+	Overdue      bool       // a drawing deadline passed with an entry still missing
+	LastReminder *time.Time
+}
+
+// Required reports whether the inquiry's trip needs licenses at all.
+func Required(inq *db.Inquiry) bool {
+	return len(data.TripLicenses(inq.TripSlug)) > 0
+}
+
+// ForInquiry builds the license status for an inquiry. Hunters come from the
+// guest manifest when the client has filled it in, otherwise from the party size.
+func ForInquiry(store *db.Store, inq *db.Inquiry, now time.Time) (*Status, error) {
+	reqs := data.TripLicenses(inq.TripSlug)
+	st := &Status{Inquiry: *inq}
+	if len(reqs) == 0 {
+		return st, nil
+	}
+
+	guests, err := store.GuestsByInquiry(inq.ID)
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := store.LicensesByInquiry(inq.ID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]db.HuntLicense, len(recorded))
+	for _, l := range recorded {
+		byKey[fmt.Sprintf("%d|%s", l.Hunter, l.Type)] = l
+	}
+
+	count := len(guests)
+	if count == 0 {
+		count = inq.Headcount()
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for pos := 1; pos <= count; pos++ {
+		h := Hunter{Position: pos, Name: fmt.Sprintf("Hunter %d", pos)}
+		if pos <= len(guests) {
+			h.Name = guests[pos-1].Name
+			h.ManifestLicense = guests[pos-1].LicenseNumber
+		} else if pos == 1 {
+			h.Name = inq.Name
+		}
+		for _, req := range reqs {
+			e := Entry{LicenseRequirement: req}
+			if l, ok := byKey[fmt.Sprintf("%d|%s", pos, req.Type)]; ok {
+				e.LicenseNumber = l.LicenseNumber
+				e.TagNumber = l.TagNumber
+			}
+			if req.Drawing {
+				if d, ok := applicableDeadline(req, inq, today); ok {
+					e.DueBy = &d
+				}
+			}
+			if e.Missing() {
+				st.Missing++
+				if e.DueBy != nil {
+					if e.DueBy.Before(today) {
+						st.Overdue = true
+					} else if st.NextDeadline == nil || e.DueBy.Before(*st.NextDeadline) {
+						st.NextDeadline = e.DueBy
+					}
+				}
+			}
+			h.Entries = append(h.Entries, e)
+		}
+		st.Hunters = append(st.Hunters, h)
+	}
+
+	st.LastReminder, err = store.LastNotified(inq.ID, "license-")
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// applicableDeadline returns the drawing deadline for the hunt's season: the
+// last one before the trip starts, or the next one if dates aren't set yet.
+func applicableDeadline(req data.LicenseRequirement, inq *db.Inquiry, today time.Time) (time.Time, bool) {
+	if inq.TripStart != nil {
+		return req.DeadlineBefore(*inq.TripStart)
+	}
+	d, ok := req.DeadlineBefore(today.AddDate(1, 0, -1))
+	return d, ok
+}
+
+// Outstanding returns booked hunts that haven't started yet and are still
+// missing license numbers, most urgent first.
+func Outstanding(store *db.Store, now time.Time) ([]Status, error) {
+	booked, err := store.ListInquiries("booked")
+	if err != nil {
+		return nil, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var out []Status
+	for i := range booked {
+		inq := &booked[i]
+		if !Required(inq) || (inq.TripEnd != nil && inq.TripEnd.Before(today)) {
+			continue
+		}
+		st, err := ForInquiry(store, inq, now)
+		if err != nil {
+			return nil, err
+		}
+		if st.Missing > 0 {
+			out = append(out, *st)
+		}
+	}
+
+	// Overdue first, then by nearest deadline or trip start.
+	urgency := func(s Status) time.Time {
+		if s.Overdue {
+			return time.Time{}
+		}
+		t := time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+		if s.NextDeadline != nil {
+			t = *s.NextDeadline
+		}
+		if s.Inquiry.TripStart != nil && s.Inquiry.TripStart.Before(t) {
+			t = *s.Inquiry.TripStart
+		}
+		return t
+	}
+	sort.SliceStable(out, func(i, j int) bool { return urgency(out[i]).Before(urgency(out[j])) })
+	return out, nil
+}
+
+// deadlineStages and tripStages are how many days out reminders go out.
+var (
+	deadlineStages = []int{30, 7}
+	tripStages     = []int{14, 3}
+)
+
+// SendReminders emails the lead client of each hunt still missing licenses
+// when a drawing deadline or the trip itself is getting close. Each stage is
+// sent once per hunt.
+func SendReminders(store *db.Store, sender mail.Sender, now time.Time) error {
+	statuses, err := Outstanding(store, now)
+	if err != nil {
+		return fmt.Errorf("outstanding licenses: %w", err)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	sent, failed := 0, 0
+	for _, st := range statuses {
+		var due []string
+		var reasons []string
+		if st.NextDeadline != nil {
+			days := int(st.NextDeadline.Sub(today).Hours() / 24)
+			if kind, ok := stage("license-deadline", *st.NextDeadline, deadlineStages, days); ok {
+				due = append(due, kind)
+				reasons = append(reasons, fmt.Sprintf("The drawing deadline is %s (%s).", st.NextDeadline.Format("Monday, January 2"), daysAway(days)))
+			}
+		}
+		if st.Inquiry.TripStart != nil {
+			days := int(st.Inquiry.TripStart.Sub(today).Hours() / 24)
+			if kind, ok := stage("license-trip", *st.Inquiry.TripStart, tripStages, days); ok {
+				due = append(due, kind)
+				reasons = append(reasons, fmt.Sprintf("Your hunt starts %s (%s).", st.Inquiry.TripStart.Format("Monday, January 2"), daysAway(days)))
+			}
+		}
+
+		var pending []string
+		for _, kind := range due {
+			done, err := store.Notified(st.Inquiry.ID, kind)
+			if err != nil {
+				return err
+			}
+			if !done {
+				pending = append(pending, kind)
+			}
+		}
+		if len(pending) == 0 || st.Inquiry.Email == "" {
+			continue
+		}
+
+		if err := sender.Send(reminderMessage(st, reasons, today)); err != nil {
+			log.Printf("[licenses] reminder for inquiry #%d failed: %v", st.Inquiry.ID, err)
+			metrics.EmailFailures.Inc("license-reminder")
+			failed++
+			continue
+		}
+		for _, kind := range pending {
+			if err := store.RecordNotification(st.Inquiry.ID, kind); err != nil {
+				return err
+			}
+		}
+		sent++
+		metrics.EmailsSent.Inc("license-reminder")
+	}
+	metrics.EmailQueue.Set(float64(failed), "license-reminder")
+	if sent > 0 {
+		log.Printf("[licenses] sent %d reminder(s)", sent)
+	}
+	return nil
+}
+
+// stage returns the notification kind for the tightest stage the date has
+// entered, e.g. "license-trip:2026-10-01:3".
+func stage(prefix string, date time.Time, stages []int, days int) (string, bool) {
+	if days < 0 {
+		return "", false
+	}
+	kind := ""
+	for _, s := range stages {
+		if days <= s {
+			kind = fmt.Sprintf("%s:%s:%d", prefix, date.Format(db.DateLayout), s)
+		}
+	}
+	return kind, kind != ""
+}
+
+func daysAway(days int) string {
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	}
+	return fmt.Sprintf("in %d days", days)
+}
+
+func reminderMessage(st Status, reasons []string, today time.Time) mail.Message {
+	var b strings.Builder
+	first := strings.Fields(st.Inquiry.Name)
+	name := st.Inquiry.Name
+	if len(first) > 0 {
+		name = first[0]
+	}
+	fmt.Fprintf(&b, "Hi %s,\n\n", name)
+	fmt.Fprintf(&b, "We still need license numbers for your %s before we can hunt.\n", st.Inquiry.TripName)
+	for _, r := range reasons {
+		fmt.Fprintf(&b, "%s\n", r)
+	}
+	b.WriteString("\nStill needed:\n")
+	for _, h := range st.Hunters {
+		for _, e := range h.Entries {
+			if !e.Missing() {
+				continue
+			}
+			fmt.Fprintf(&b, "  - %s: %s", h.Name, e.Type)
+			if e.DueBy != nil && e.DueBy.Before(today) {
+				b.WriteString(" (drawing deadline passed, call us about leftover licenses)")
+			} else if e.DueBy != nil {
+				fmt.Fprintf(&b, " (apply by %s)", e.DueBy.Format("January 2"))
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\nReply to this email with your license and tag numbers, or call Forrest at (406) 459-5352.\n")
+	b.WriteString("Licenses are sold through Montana FWP: https://fwp.mt.gov/buyandapply\n\n")
+	fmt.Fprintf(&b, "MT Hunt & Fish Outfitters\n%s\n", data.BaseURL())
+
+	return mail.Message{
+		To:      st.Inquiry.Email,
+		Subject: "License reminder: " + st.Inquiry.TripName,
+		Body:    b.String(),
+	}
+}
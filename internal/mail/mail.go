@@ -0,0 +1,106 @@
+// Package mail sends plain-text email over SMTP, falling back to logging
+// messages when no SMTP server is configured.
+package mail
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email.
+type Sender interface {
+	Send(m Message) error
+}
+
+// FromEnv returns an SMTP sender configured from SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME, SMTP_PASSWORD and MAIL_FROM, or a logging sender if
+// SMTP_HOST is unset.
+func FromEnv() Sender {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("[mail] SMTP_HOST not set — emails will be logged, not sent")
+		return LogSender{}
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "MT Hunt & Fish Outfitters <noreply@mthuntfish.com>"
+	}
+	return &SMTPSender{
+		Addr:     net.JoinHostPort(host, port),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
+
+// SMTPSender sends mail through an SMTP relay using STARTTLS when offered.
+type SMTPSender struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	From     string // "Name <addr>" or bare address
+}
+
+// Send delivers a message.
+func (s *SMTPSender) Send(m Message) error {
+	if m.To == "" {
+		return fmt.Errorf("mail: no recipient")
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := net.SplitHostPort(s.Addr)
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	if err := smtp.SendMail(s.Addr, auth, address(s.From), []string{m.To}, format(s.From, m)); err != nil {
+		return fmt.Errorf("mail: send to %s: %w", m.To, err)
+	}
+	return nil
+}
+
+// LogSender writes messages to the log instead of sending them.
+type LogSender struct{}
+
+// Send logs the message.
+func (LogSender) Send(m Message) error {
+	log.Printf("[mail] to=%s subject=%q (not sent)\n%s", m.To, m.Subject, m.Body)
+	return nil
+}
+
+// address extracts the bare address from "Name <addr>".
+func address(from string) string {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		return strings.TrimSuffix(from[i+1:], ">")
+	}
+	return from
+}
+
+// format builds the RFC 5322 message bytes.
+func format(from string, m Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// The site's metrics. Label values must come from a small fixed set (route
+// patterns, outcomes), never from user input, or the series multiply.
+var (
+	HTTPRequestDuration = NewHistogram("packstring_http_request_duration_seconds",
+		"Time to serve HTTP requests, by matched route pattern.",
+		DefaultBuckets, "method", "route", "code")
+
+	Inquiries = NewCounter("packstring_inquiries_total",
+		"Contact form submissions by outcome: saved, spam (quarantined), rate_limited (refused), spooled (kept for replay), invalid or failed (lost).",
+		"outcome")
+
+	DBErrors = NewCounter("packstring_db_errors_total",
+		"Database errors on paths that must not lose data, by operation.",
+		"op")
+
+	StripeWebhooks = NewCounter("packstring_stripe_webhooks_total",
+		"Stripe webhook deliveries by event type and outcome.",
+		"event", "outcome")
+
+	InquirySpool = NewGauge("packstring_inquiry_spool_depth",
+		"Inquiries waiting in the spool file for the database to take them.")
+
+	EmailQueue = NewGauge("packstring_email_queue_depth",
+		"Emails due but not yet sent after the last run of their job, by kind.",
+		"kind")
+
+	EmailsSent = NewCounter("packstring_emails_sent_total",
+		"Emails sent by the scheduled jobs, by kind.",
+		"kind")
+
+	EmailFailures = NewCounter("packstring_email_send_failures_total",
+		"Emails the scheduled jobs failed to send, by kind. Each is retried on the job's next run.",
+		"kind")
+
+	AvailabilityReloads = NewCounter("packstring_availability_reloads_total",
+		"Loads of availability.yaml by result: ok or error.",
+		"result")
+)
+
+var startTime = time.Now()
+
+func init() {
+	NewGaugeFunc("process_start_time_seconds",
+		"Start time of the process since the Unix epoch in seconds.",
+		func() float64 { return float64(startTime.UnixNano()) / 1e9 })
+	NewGaugeFunc("go_goroutines",
+		"Number of goroutines that currently exist.",
+		func() float64 { return float64(runtime.NumGoroutine()) })
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// ErrorRenderer writes the response for an error status. detail is the
+// handler's plain-text message, e.g. "Inquiry not found", or "" if it had
+// none worth repeating.
+type ErrorRenderer func(w http.ResponseWriter, r *http.Request, status int, detail string)
+
+// maxDetail bounds how much of a plain-text error body is kept as detail.
+const maxDetail = 512
+
+// Errors replaces plain-text error responses, the ones written by
+// http.Error and http.NotFound and the ServeMux's own 404 and 405, with
+// whatever render produces. Handlers keep calling http.Error; responses
+// they write themselves (a form re-rendered with a 422, an htmx toast) are
+// left alone. Requests that accept neither HTML nor JSON, e.g. an <img>
+// fetching a missing file, keep the plain text.
+func Errors(render ErrorRenderer) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept := r.Header.Get("Accept")
+			if !strings.Contains(accept, "text/html") && !strings.Contains(accept, "json") && r.Header.Get("HX-Request") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ew := &errorWriter{ResponseWriter: w}
+			next.ServeHTTP(ew, r)
+			if ew.status != 0 {
+				h := w.Header()
+				h.Del("Content-Type")
+				h.Del("Content-Length")
+				detail := strings.TrimSpace(ew.detail.String())
+				if detail == http.StatusText(ew.status) || strings.HasPrefix(detail, "404 page not found") {
+					detail = ""
+				}
+				render(w, r, ew.status, detail)
+			}
+		})
+	}
+}
+
+// errorWriter holds back a plain-text error so it can be replaced.
+type errorWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	status      int // set when the response is being replaced
+	detail      bytes.Buffer
+}
+
+func (w *errorWriter) WriteHeader(status int) {
+	if w.wroteHeader || status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.wroteHeader = true
+	if status >= 400 && strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.status != 0 {
+		if room := maxDetail - w.detail.Len(); room > 0 {
+			w.detail.Write(b[:min(len(b), room)])
+		}
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (w *errorWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *errorWriter) Flush() {
+	if w.status != 0 {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/metrics"
+)
+
+// Metrics records each request's latency against the ServeMux pattern it
+// matched, so /reports/{slug}/ is one series however many reports exist.
+// It must sit inside RequestID and outside anything that copies the
+// request, since the mux sets the pattern on the request it's handed.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := wrap(w)
+		defer func() {
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := r.Pattern
+			if i := strings.IndexByte(route, ' '); i >= 0 {
+				route = route[i+1:] // drop the method, it has its own label
+			}
+			if route == "" {
+				route = "unmatched"
+			}
+			metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), method(r.Method), route, strconv.Itoa(status))
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// method returns m if it's a standard method, so a client can't mint new
+// series by inventing methods.
+func method(m string) string {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return m
+	}
+	return "other"
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import "net/http"
+
+// Observe calls fn with each request and the status it was answered with,
+// once the handler returns. Like Metrics it must sit outside anything that
+// copies the request, so fn sees the pattern the mux matched.
+func Observe(fn func(r *http.Request, status int)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrap(w)
+			next.ServeHTTP(rw, r)
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			fn(r, status)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover turns a panicking handler into a logged error and a 500 page
+// rendered by errorPage, instead of a dropped connection. If the handler
+// had already started its response there is nothing to replace, so the
+// connection is closed as net/http would.
+func Recover(logger *slog.Logger, errorPage http.HandlerFunc) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrap(w)
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				logger.ErrorContext(r.Context(), "panic",
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("error", fmt.Sprint(p)),
+					slog.String("request_id", GetRequestID(r.Context())),
+					slog.String("stack", string(debug.Stack())),
+				)
+				if rw.started() {
+					panic(http.ErrAbortHandler)
+				}
+				errorPage(rw, r)
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
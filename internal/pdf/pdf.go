@@ -0,0 +1,226 @@
+// Package pdf writes simple text-only PDF documents using the standard
+// Helvetica fonts, which every PDF reader ships with. It covers what the
+// admin needs for printable records (headings, wrapped paragraphs, page
+// breaks) without pulling in a layout engine.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// US Letter in points, with one-inch margins.
+const (
+	pageWidth    = 612.0
+	pageHeight   = 792.0
+	margin       = 72.0
+	contentWidth = pageWidth - 2*margin
+)
+
+type font int
+
+const (
+	regular font = iota
+	bold
+)
+
+// line is a single positioned run of text on a page.
+type line struct {
+	font font
+	size float64
+	y    float64
+	text string
+}
+
+// Document accumulates text and lays it out top-to-bottom across pages.
+type Document struct {
+	pages [][]line
+	y     float64
+}
+
+// New returns an empty document with one blank page.
+func New() *Document {
+	d := &Document{}
+	d.newPage()
+	return d
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, nil)
+	d.y = pageHeight - margin
+}
+
+// Heading adds a bold heading line at the given point size.
+func (d *Document) Heading(text string, size float64) {
+	d.write(bold, size, text)
+	d.Space(size * 0.4)
+}
+
+// Text adds a paragraph in the regular face, wrapped to the page width.
+func (d *Document) Text(text string, size float64) {
+	d.write(regular, size, text)
+}
+
+// Label adds a short bold line, e.g. a field name above its value.
+func (d *Document) Label(text string, size float64) {
+	d.write(bold, size, text)
+}
+
+// Space moves the cursor down by the given number of points.
+func (d *Document) Space(pts float64) {
+	d.y -= pts
+}
+
+// Rule separates sections with a line of dashes spanning the content width.
+func (d *Document) Rule() {
+	n := int(contentWidth / textWidth(regular, 9, "-"))
+	d.write(regular, 9, strings.Repeat("-", n))
+	d.Space(4)
+}
+
+func (d *Document) write(f font, size float64, text string) {
+	leading := size * 1.4
+	for _, para := range strings.Split(text, "\n") {
+		for _, l := range wrap(f, size, para) {
+			if d.y-leading < margin {
+				d.newPage()
+			}
+			d.y -= leading
+			p := len(d.pages) - 1
+			d.pages[p] = append(d.pages[p], line{font: f, size: size, y: d.y, text: l})
+		}
+	}
+}
+
+// wrap breaks a paragraph into lines that fit the content width.
+func wrap(f font, size float64, para string) []string {
+	words := strings.Fields(para)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var lines []string
+	cur := words[0]
+	for _, w := range words[1:] {
+		if textWidth(f, size, cur+" "+w) > contentWidth {
+			lines = append(lines, cur)
+			cur = w
+			continue
+		}
+		cur += " " + w
+	}
+	return append(lines, cur)
+}
+
+// Bytes renders the document as a complete PDF file.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1–4: catalog, page tree, and the two fonts. Each page then
+	// takes two objects (page + content stream), starting at 5.
+	nPages := len(d.pages)
+	kids := make([]string, nPages)
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), nPages))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range d.pages {
+		var content bytes.Buffer
+		for _, l := range lines {
+			name := "F1"
+			if l.font == bold {
+				name = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", name, l.size, margin, l.y, escape(encode(l.text)))
+		}
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// winAnsi maps the non-Latin-1 characters that show up in site copy to their
+// WinAnsiEncoding byte values.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '•': 0x95,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// encode converts UTF-8 text to WinAnsiEncoding bytes, replacing anything
+// the standard fonts can't show with "?".
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			b.WriteByte(winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return r.Replace(s)
+}
+
+// textWidth approximates the rendered width of s in points using the
+// Helvetica metrics for ASCII; other characters use an average width.
+func textWidth(f font, size float64, s string) float64 {
+	widths := helveticaWidths
+	if f == bold {
+		widths = helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Glyph widths (1/1000 em) for ASCII 32–126, from the Adobe core font metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
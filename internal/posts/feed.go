@@ -0,0 +1,160 @@
+package posts
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// Feed describes the channel-level fields shared by the RSS and Atom feeds.
+type Feed struct {
+	Title       string
+	Description string
+	SiteURL     string // absolute base URL without trailing slash
+	Path        string // listing path, e.g. "/reports/"
+	SelfPath    string // path of the feed itself, e.g. "/reports/feed.xml"
+}
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Self          rssSelf   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+}
+
+// WriteRSS writes posts as an RSS 2.0 feed.
+func WriteRSS(w io.Writer, f Feed, posts []Post) error {
+	ch := rssChannel{
+		Title:       f.Title,
+		Link:        f.SiteURL + f.Path,
+		Description: f.Description,
+		Language:    "en-us",
+		Self:        rssSelf{Href: f.SiteURL + f.SelfPath, Rel: "self", Type: "application/rss+xml"},
+	}
+	if len(posts) > 0 {
+		ch.LastBuildDate = posts[0].Date.Format(time.RFC1123Z)
+	}
+	for _, p := range posts {
+		ch.Items = append(ch.Items, rssItem{
+			Title:       p.Title,
+			Link:        f.SiteURL + p.URL(),
+			GUID:        f.SiteURL + p.URL(),
+			PubDate:     p.Date.Format(time.RFC1123Z),
+			Description: string(p.HTML),
+			Categories:  p.Tags,
+		})
+	}
+	return writeXML(w, rssDoc{Version: "2.0", Atom: "http://www.w3.org/2005/Atom", Channel: ch})
+}
+
+type atomDoc struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary"`
+	Content    atomText       `xml:"content"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// WriteAtom writes posts as an Atom 1.0 feed.
+func WriteAtom(w io.Writer, f Feed, posts []Post) error {
+	doc := atomDoc{
+		Title: f.Title,
+		ID:    f.SiteURL + f.Path,
+		Links: []atomLink{
+			{Href: f.SiteURL + f.Path},
+			{Href: f.SiteURL + f.SelfPath, Rel: "self", Type: "application/atom+xml"},
+		},
+		Author: atomAuthor{Name: f.Title},
+	}
+	if len(posts) > 0 {
+		doc.Updated = posts[0].Date.Format(time.RFC3339)
+	} else {
+		doc.Updated = time.Now().Format(time.RFC3339)
+	}
+	for _, p := range posts {
+		e := atomEntry{
+			Title:     p.Title,
+			ID:        f.SiteURL + p.URL(),
+			Link:      atomLink{Href: f.SiteURL + p.URL()},
+			Published: p.Date.Format(time.RFC3339),
+			Updated:   p.Date.Format(time.RFC3339),
+			Summary:   p.Summary,
+			Content:   atomText{Type: "html", Body: string(p.HTML)},
+		}
+		for _, t := range p.Tags {
+			e.Categories = append(e.Categories, atomCategory{Term: t})
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,236 @@
+package posts
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Render converts the Markdown subset used in reports to HTML: ATX headings,
+// paragraphs, bullet and numbered lists, blockquotes, fenced code, horizontal
+// rules, and inline code, links, images, bold and italics. Raw HTML in the
+// source is escaped rather than passed through.
+func Render(src string) template.HTML {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var b strings.Builder
+	renderBlocks(&b, lines)
+	return template.HTML(b.String())
+}
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	hrRe      = regexp.MustCompile(`^(\*\s*){3,}$|^(-\s*){3,}$|^(_\s*){3,}$`)
+	bulletRe  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	orderedRe = regexp.MustCompile(`^\d{1,9}[.)]\s+(.*)$`)
+)
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			i++
+			var code []string
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+				code = append(code, lines[i])
+				i++
+			}
+			i++ // closing fence
+			if lang != "" {
+				b.WriteString(`<pre><code class="language-` + html.EscapeString(lang) + `">`)
+			} else {
+				b.WriteString("<pre><code>")
+			}
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+
+		case headingRe.MatchString(trimmed):
+			m := headingRe.FindStringSubmatch(trimmed)
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+			i++
+
+		case hrRe.MatchString(trimmed):
+			b.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quote []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(q, " "))
+				i++
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quote)
+			b.WriteString("</blockquote>\n")
+
+		case bulletRe.MatchString(trimmed), orderedRe.MatchString(trimmed):
+			re, tag := bulletRe, "ul"
+			if !bulletRe.MatchString(trimmed) {
+				re, tag = orderedRe, "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for i < len(lines) {
+				t := strings.TrimSpace(lines[i])
+				m := re.FindStringSubmatch(t)
+				if m == nil {
+					break
+				}
+				item := m[1]
+				i++
+				// Indented lines continue the current item.
+				for i < len(lines) && strings.TrimSpace(lines[i]) != "" &&
+					(lines[i][0] == ' ' || lines[i][0] == '\t') && re.FindStringSubmatch(strings.TrimSpace(lines[i])) == nil {
+					item += " " + strings.TrimSpace(lines[i])
+					i++
+				}
+				b.WriteString("<li>" + renderInline(item) + "</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+
+		default:
+			var para []string
+			for i < len(lines) {
+				t := strings.TrimSpace(lines[i])
+				if t == "" || strings.HasPrefix(t, "```") || strings.HasPrefix(t, ">") ||
+					headingRe.MatchString(t) || hrRe.MatchString(t) || bulletRe.MatchString(t) {
+					break
+				}
+				para = append(para, t)
+				i++
+			}
+			b.WriteString("<p>" + renderInline(strings.Join(para, "\n")) + "</p>\n")
+		}
+	}
+}
+
+// renderInline handles code spans, images, links and emphasis within a block.
+func renderInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()!#>-", s[i+1]) >= 0:
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				b.WriteString("<code>" + html.EscapeString(s[i+1:i+1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+
+		case c == '!' && strings.HasPrefix(s[i+1:], "["):
+			if text, url, n, ok := parseLink(s[i+1:]); ok {
+				b.WriteString(`<img src="` + html.EscapeString(safeURL(url)) + `" alt="` + html.EscapeString(text) + `" loading="lazy">`)
+				i += n + 1
+				continue
+			}
+
+		case c == '[':
+			if text, url, n, ok := parseLink(s[i:]); ok {
+				href := safeURL(url)
+				attrs := ""
+				if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+					attrs = ` rel="noopener"`
+				}
+				b.WriteString(`<a href="` + html.EscapeString(href) + `"` + attrs + `>` + renderInline(text) + `</a>`)
+				i += n
+				continue
+			}
+
+		case c == '*' || c == '_':
+			delim := string(c)
+			if strings.HasPrefix(s[i:], delim+delim) {
+				delim += delim
+			}
+			// Underscores inside words (snake_case, file_names) are literal.
+			if c == '_' && i > 0 && isWordByte(s[i-1]) {
+				break
+			}
+			rest := s[i+len(delim):]
+			if end := strings.Index(rest, delim); end > 0 && rest[0] != ' ' && rest[end-1] != ' ' {
+				tag := "em"
+				if len(delim) == 2 {
+					tag = "strong"
+				}
+				b.WriteString("<" + tag + ">" + renderInline(rest[:end]) + "</" + tag + ">")
+				i += len(delim) + end + len(delim)
+				continue
+			}
+
+		case c == '\n':
+			b.WriteString("\n")
+			i++
+			continue
+		}
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// parseLink parses "[text](url)" or `[text](url "title")` at the start of s,
+// returning the text, the URL and the number of bytes consumed.
+func parseLink(s string) (text, url string, n int, ok bool) {
+	depth, close := 0, -1
+	for i := 0; i < len(s); i++ {
+		if s[i] == '[' {
+			depth++
+		} else if s[i] == ']' {
+			depth--
+			if depth == 0 {
+				close = i
+				break
+			}
+		}
+	}
+	if close < 0 || close+1 >= len(s) || s[close+1] != '(' {
+		return "", "", 0, false
+	}
+	end := strings.IndexByte(s[close+2:], ')')
+	if end < 0 {
+		return "", "", 0, false
+	}
+	target := strings.TrimSpace(s[close+2 : close+2+end])
+	if sp := strings.IndexAny(target, " \t"); sp >= 0 {
+		target = target[:sp] // drop an optional "title"
+	}
+	return s[1:close], target, close + 3 + end, true
+}
+
+// safeURL drops URLs with schemes other than http, https, mailto and tel so
+// Markdown links can't smuggle in javascript: hrefs.
+func safeURL(u string) string {
+	lower := strings.ToLower(u)
+	if i := strings.IndexByte(lower, ':'); i >= 0 && !strings.ContainsAny(lower[:i], "/?#") {
+		switch lower[:i] {
+		case "http", "https", "mailto", "tel":
+		default:
+			return "#"
+		}
+	}
+	return u
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+var tagRe = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips tags from rendered HTML and collapses whitespace.
+func plainText(h template.HTML) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagRe.ReplaceAllString(string(h), " "))), " ")
+}
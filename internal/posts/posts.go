@@ -0,0 +1,352 @@
+// Package posts loads fishing reports and other blog posts from Markdown
+// files with YAML front matter.
+package posts
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatter is the YAML block between "---" lines at the top of a post.
+type frontMatter struct {
+	Title   string   `yaml:"title"`
+	Slug    string   `yaml:"slug"`
+	Date    string   `yaml:"date"`
+	Summary string   `yaml:"summary"`
+	Tags    []string `yaml:"tags"`
+	Hero    string   `yaml:"hero"`
+	HeroAlt string   `yaml:"hero_alt"`
+	Trips   []string `yaml:"trips"`
+	Draft   bool     `yaml:"draft"`
+
+	// Optional river conditions
+	River      string   `yaml:"river"`
+	FlowCFS    *int     `yaml:"flow_cfs"`
+	WaterTempF *int     `yaml:"water_temp_f"`
+	Hatches    []string `yaml:"hatches"`
+	Flies      []string `yaml:"flies"`
+}
+
+// Conditions are the river conditions a fishing report describes.
+type Conditions struct {
+	River      string
+	FlowCFS    *int
+	WaterTempF *int
+	Hatches    []string
+	Flies      []string
+}
+
+// Post is a single parsed report.
+type Post struct {
+	Slug    string
+	Title   string
+	Date    time.Time // publish time; posts dated in the future stay hidden until then
+	Updated time.Time // last edit, for sitemaps and feeds
+	Summary string
+	Tags    []string
+	Hero    string // base path without size suffix, e.g. "/static/img/gallery/gallery-03"
+	HeroAlt string
+	Trips   []string // related trip slugs, e.g. "jet-boat"
+	Draft   bool
+	HTML    template.HTML
+
+	Conditions *Conditions // nil for posts that aren't about a particular water
+}
+
+// URL returns the post's path on the site.
+func (p Post) URL() string {
+	return "/reports/" + p.Slug + "/"
+}
+
+// Published reports whether the post is live at the given time.
+func (p Post) Published(now time.Time) bool {
+	return !p.Draft && !p.Date.After(now)
+}
+
+// dateLayouts are the accepted front matter date formats, read in local time.
+var dateLayouts = []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"}
+
+// Parse reads a post from Markdown source. The slug defaults to fallbackSlug
+// when the front matter doesn't set one.
+func Parse(src []byte, fallbackSlug string) (Post, error) {
+	src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	rest, ok := bytes.CutPrefix(src, []byte("---\n"))
+	if !ok {
+		return Post{}, fmt.Errorf("missing front matter")
+	}
+	head, body, ok := bytes.Cut(rest, []byte("\n---\n"))
+	if !ok {
+		return Post{}, fmt.Errorf("unterminated front matter")
+	}
+
+	var fm frontMatter
+	if err := yaml.Unmarshal(head, &fm); err != nil {
+		return Post{}, fmt.Errorf("front matter: %w", err)
+	}
+	if strings.TrimSpace(fm.Title) == "" {
+		return Post{}, fmt.Errorf("title is required")
+	}
+
+	var date time.Time
+	var err error
+	for _, layout := range dateLayouts {
+		if date, err = time.ParseInLocation(layout, strings.TrimSpace(fm.Date), time.Local); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return Post{}, fmt.Errorf("date %q: want YYYY-MM-DD or YYYY-MM-DD HH:MM", fm.Date)
+	}
+
+	p := Post{
+		Slug:    fm.Slug,
+		Title:   strings.TrimSpace(fm.Title),
+		Date:    date,
+		Summary: strings.TrimSpace(fm.Summary),
+		Tags:    fm.Tags,
+		Hero:    fm.Hero,
+		HeroAlt: fm.HeroAlt,
+		Trips:   fm.Trips,
+		Draft:   fm.Draft,
+		HTML:    Render(string(body)),
+	}
+	if p.Slug == "" {
+		p.Slug = fallbackSlug
+	}
+	if p.HeroAlt == "" {
+		p.HeroAlt = p.Title
+	}
+	if p.Summary == "" {
+		p.Summary = Summarize(p.HTML, 200)
+	}
+	if fm.River != "" {
+		p.Conditions = &Conditions{
+			River:      fm.River,
+			FlowCFS:    fm.FlowCFS,
+			WaterTempF: fm.WaterTempF,
+			Hatches:    fm.Hatches,
+			Flies:      fm.Flies,
+		}
+	}
+	return p, nil
+}
+
+// Summarize returns the first paragraph of rendered HTML as plain text,
+// cut at a word boundary near max bytes.
+func Summarize(h template.HTML, max int) string {
+	s := string(h)
+	if start := strings.Index(s, "<p>"); start >= 0 {
+		s = s[start:]
+		if end := strings.Index(s, "</p>"); end >= 0 {
+			s = s[:end]
+		}
+	}
+	text := plainText(template.HTML(s))
+	if len(text) <= max {
+		return text
+	}
+	if cut := strings.LastIndexByte(text[:max], ' '); cut > 0 {
+		text = text[:cut]
+	}
+	return strings.TrimRight(text, ",.;:") + "…"
+}
+
+// Store loads and caches posts from a directory of .md files.
+type Store struct {
+	fsys    fs.FS
+	dir     string
+	devMode bool
+
+	mu      sync.RWMutex
+	posts   []Post // newest first, including drafts and scheduled posts
+	modTime time.Time
+	count   int
+}
+
+// NewStore creates a store that reads posts from dir in fsys. In dev mode
+// the directory is re-checked on every read so edits show up without a
+// restart. A missing directory or a bad file logs a warning but does not
+// crash the server.
+func NewStore(fsys fs.FS, dir string, devMode bool) *Store {
+	s := &Store{fsys: fsys, dir: dir, devMode: devMode}
+	s.load()
+	return s
+}
+
+func (s *Store) load() {
+	files, err := fs.Glob(s.fsys, path.Join(s.dir, "*.md"))
+	if err != nil {
+		log.Printf("[posts] warning: %v", err)
+		return
+	}
+
+	var posts []Post
+	seen := make(map[string]string)
+	for _, f := range files {
+		raw, err := fs.ReadFile(s.fsys, f)
+		if err != nil {
+			log.Printf("[posts] warning: cannot read %s: %v", f, err)
+			continue
+		}
+		p, err := Parse(raw, fileSlug(f))
+		if err != nil {
+			log.Printf("[posts] warning: skipping %s: %v", f, err)
+			continue
+		}
+		p.Updated = p.Date
+		if info, err := fs.Stat(s.fsys, f); err == nil && info.ModTime().After(p.Date) {
+			p.Updated = info.ModTime()
+		}
+		if other, dup := seen[p.Slug]; dup {
+			log.Printf("[posts] warning: skipping %s: slug %q already used by %s", f, p.Slug, other)
+			continue
+		}
+		seen[p.Slug] = f
+		posts = append(posts, p)
+	}
+	sort.SliceStable(posts, func(a, b int) bool { return posts[a].Date.After(posts[b].Date) })
+
+	modTime, count := s.stat()
+	s.mu.Lock()
+	s.posts = posts
+	s.modTime = modTime
+	s.count = count
+	s.mu.Unlock()
+
+	log.Printf("[posts] loaded %d posts from %s", len(posts), s.dir)
+}
+
+// datePrefix matches the optional "2026-06-14-" filename prefix that keeps
+// the reports directory sorted by date.
+var datePrefix = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-`)
+
+// fileSlug derives a slug from a post's filename, dropping the extension and
+// any date prefix.
+func fileSlug(name string) string {
+	return datePrefix.ReplaceAllString(strings.TrimSuffix(path.Base(name), ".md"), "")
+}
+
+// stat returns the newest modification time and the number of .md files in
+// the directory, which together detect edits, additions and deletions.
+func (s *Store) stat() (time.Time, int) {
+	files, _ := fs.Glob(s.fsys, path.Join(s.dir, "*.md"))
+	var newest time.Time
+	for _, f := range files {
+		if info, err := fs.Stat(s.fsys, f); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest, len(files)
+}
+
+func (s *Store) reloadIfChanged() {
+	modTime, count := s.stat()
+	s.mu.RLock()
+	changed := !modTime.Equal(s.modTime) || count != s.count
+	s.mu.RUnlock()
+	if changed {
+		s.load()
+	}
+}
+
+func (s *Store) all() []Post {
+	if s.devMode {
+		s.reloadIfChanged()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.posts
+}
+
+// Published returns every post live at the given time, newest first.
+func (s *Store) Published(now time.Time) []Post {
+	var out []Post
+	for _, p := range s.all() {
+		if p.Published(now) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Find returns the post with the given slug. Drafts and scheduled posts are
+// only returned in dev mode so authors can preview them.
+func (s *Store) Find(slug string, now time.Time) (Post, bool) {
+	for _, p := range s.all() {
+		if p.Slug == slug && (p.Published(now) || s.devMode) {
+			return p, true
+		}
+	}
+	return Post{}, false
+}
+
+// Page is one page of a paginated post listing.
+type Page struct {
+	Posts  []Post
+	Number int // 1-based
+	Total  int // number of pages, at least 1
+}
+
+// HasPrev reports whether there is a newer page.
+func (p Page) HasPrev() bool { return p.Number > 1 }
+
+// HasNext reports whether there is an older page.
+func (p Page) HasNext() bool { return p.Number < p.Total }
+
+// Prev returns the previous page number.
+func (p Page) Prev() int { return p.Number - 1 }
+
+// Next returns the next page number.
+func (p Page) Next() int { return p.Number + 1 }
+
+// Merge combines post lists newest first. When two posts share a slug the
+// one from the earlier list wins.
+func Merge(lists ...[]Post) []Post {
+	var out []Post
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, p := range list {
+			if seen[p.Slug] {
+				continue
+			}
+			seen[p.Slug] = true
+			out = append(out, p)
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Date.After(out[b].Date) })
+	return out
+}
+
+// Latest returns up to n posts from the front of a newest-first list.
+func Latest(posts []Post, n int) []Post {
+	if len(posts) > n {
+		posts = posts[:n]
+	}
+	return posts
+}
+
+// Paginate returns page number n (1-based) of posts. ok is false when n is
+// out of range.
+func Paginate(posts []Post, n, perPage int) (page Page, ok bool) {
+	total := (len(posts) + perPage - 1) / perPage
+	if total == 0 {
+		total = 1
+	}
+	if n < 1 || n > total {
+		return Page{}, false
+	}
+	start := (n - 1) * perPage
+	end := min(start+perPage, len(posts))
+	return Page{Posts: posts[start:end], Number: n, Total: total}, true
+}
@@ -0,0 +1,124 @@
+// Package pretrip emails booked clients their gear list and trip-prep notes
+// a set number of days before the trip starts.
+package pretrip
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firefly/packstring/internal/data"
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/mail"
+	"github.com/firefly/packstring/internal/metrics"
+)
+
+// DefaultDays is how far ahead of the trip the email goes out when
+// PRETRIP_EMAIL_DAYS is unset.
+const DefaultDays = 7
+
+// DaysFromEnv reads PRETRIP_EMAIL_DAYS, falling back to DefaultDays.
+func DaysFromEnv() int {
+	n, err := strconv.Atoi(os.Getenv("PRETRIP_EMAIL_DAYS"))
+	if err != nil || n < 1 {
+		return DefaultDays
+	}
+	return n
+}
+
+// SendEmails sends the pre-trip email to every booked client whose trip starts
+// between tomorrow and `days` days from today. Each trip gets one email per
+// start date, so a rescheduled trip gets a fresh one.
+func SendEmails(store *db.Store, sender mail.Sender, days int, now time.Time) error {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	bookings, err := store.BookingsBetween(today.AddDate(0, 0, 1), today.AddDate(0, 0, days))
+	if err != nil {
+		return fmt.Errorf("upcoming bookings: %w", err)
+	}
+
+	sent, failed := 0, 0
+	for _, b := range bookings {
+		inq := b.Inquiry
+		// BookingsBetween also returns trips already under way; only trips
+		// that haven't started yet get the email.
+		if inq.TripStart == nil || !inq.TripStart.After(today) || inq.Email == "" {
+			continue
+		}
+		trip, ok := data.FindTrip(inq.TripSlug)
+		if !ok || len(trip.Gear) == 0 {
+			continue
+		}
+
+		kind := "pretrip:" + inq.TripStart.Format(db.DateLayout)
+		done, err := store.Notified(inq.ID, kind)
+		if err != nil {
+			return err
+		}
+		if done {
+			continue
+		}
+
+		if err := sender.Send(Message(&inq, trip, b.Resources)); err != nil {
+			log.Printf("[pretrip] email for inquiry #%d failed: %v", inq.ID, err)
+			metrics.EmailFailures.Inc("pretrip")
+			failed++
+			continue // retried on the next run, up to the day before the trip
+		}
+		if err := store.RecordNotification(inq.ID, kind); err != nil {
+			return err
+		}
+		sent++
+		metrics.EmailsSent.Inc("pretrip")
+	}
+	metrics.EmailQueue.Set(float64(failed), "pretrip")
+	if sent > 0 {
+		log.Printf("[pretrip] sent %d email(s)", sent)
+	}
+	return nil
+}
+
+// Message builds the pre-trip email for a booking.
+func Message(inq *db.Inquiry, trip data.TripSection, crew []db.Resource) mail.Message {
+	var b strings.Builder
+	name := inq.Name
+	if f := strings.Fields(name); len(f) > 0 {
+		name = f[0]
+	}
+	fmt.Fprintf(&b, "Hi %s,\n\n", name)
+	fmt.Fprintf(&b, "Your trip starts %s (%s). Here's what to bring and what to know before you head out.\n", inq.TripStart.Format("Monday, January 2"), trip.Title)
+	for _, r := range crew {
+		if r.Kind == "guide" {
+			fmt.Fprintf(&b, "Your guide is %s.\n", r.Name)
+		}
+	}
+
+	b.WriteString("\nWHAT TO BRING\n")
+	for _, g := range trip.Gear {
+		fmt.Fprintf(&b, "  [ ] %s\n", g)
+	}
+	if len(trip.Includes) > 0 {
+		b.WriteString("\nWE PROVIDE\n")
+		for _, item := range trip.Includes {
+			fmt.Fprintf(&b, "  - %s\n", item)
+		}
+	}
+	if len(trip.Prep) > 0 {
+		b.WriteString("\nBEFORE YOU GO\n")
+		for _, p := range trip.Prep {
+			fmt.Fprintf(&b, "  - %s\n", p)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nPrintable version: %s/trips/%s/gear/\n\n", data.BaseURL(), trip.Slug)
+	b.WriteString("Questions? Reply to this email or call Forrest at (406) 459-5352.\n\n")
+	fmt.Fprintf(&b, "MT Hunt & Fish Outfitters\n%s\n", data.BaseURL())
+
+	return mail.Message{
+		To:      inq.Email,
+		Subject: "Getting ready for your trip: " + trip.Title,
+		Body:    b.String(),
+	}
+}
@@ -0,0 +1,61 @@
+package resizer
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Prefix is where the endpoint is mounted.
+const Prefix = "/img/"
+
+// staticPrefix is where the images it serves live under /static/.
+const staticPrefix = "/static/img/"
+
+// errPreset reports a template asking for a size the endpoint won't serve.
+var errPreset = errors.New("unknown image preset")
+
+// FuncMap provides the image helpers to templates:
+//
+//	<img src="{{img .Image "800w"}}" srcset="{{srcset .Image}}">
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"img":    URL,
+		"srcset": SrcSet,
+	}
+}
+
+// URL returns the address of base at preset, where base is an image path
+// without its size suffix, e.g. "/static/img/trips/elk-hunting". Images
+// outside static/img keep the script's naming.
+func URL(base, preset string) (string, error) {
+	if _, ok := Presets[preset]; !ok {
+		return "", fmt.Errorf("%w %q", errPreset, preset)
+	}
+	if rest, ok := strings.CutPrefix(base, staticPrefix); ok {
+		return Prefix + preset + "/" + rest, nil
+	}
+	return base + "-" + preset + ".webp", nil
+}
+
+// SrcSet returns a srcset for base at the given width presets, or at every
+// width when none are given.
+func SrcSet(base string, presets ...string) (string, error) {
+	if len(presets) == 0 {
+		presets = widths
+	}
+	parts := make([]string, len(presets))
+	for i, name := range presets {
+		p, ok := Presets[name]
+		if !ok || p.Height != 0 {
+			return "", fmt.Errorf("%w %q in srcset", errPreset, name)
+		}
+		u, err := URL(base, name)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = fmt.Sprintf("%s %dw", u, p.Width)
+	}
+	return strings.Join(parts, ", "), nil
+}
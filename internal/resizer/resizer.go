@@ -0,0 +1,359 @@
+// Package resizer serves the site's photos at a fixed set of sizes and
+// crops. Sizes already produced, by the shell script or a gallery upload,
+// are served as they are; anything else is generated on first request from
+// the original under static/img/source and cached on disk, so adding a size
+// to a template no longer means re-running scripts/optimize-images.sh.
+package resizer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/firefly/packstring/internal/imaging"
+)
+
+// Preset is a named output size. Only presets can be requested, which
+// bounds how many variants of each image the cache can ever hold.
+type Preset struct {
+	Name   string
+	Width  int
+	Height int // 0 keeps the aspect ratio; otherwise a center crop
+}
+
+// Presets are the sizes the endpoint serves. Width presets are named like
+// the script's file suffixes so its output is reused.
+var Presets = map[string]Preset{
+	"400w":  {Name: "400w", Width: 400},
+	"800w":  {Name: "800w", Width: 800},
+	"1200w": {Name: "1200w", Width: 1200},
+	"1600w": {Name: "1600w", Width: 1600},
+	"thumb": {Name: "thumb", Width: 400, Height: 300},
+}
+
+// widths are the width presets, narrowest first.
+var widths = []string{"400w", "800w", "1200w", "1600w"}
+
+// jpegQuality matches the quality the script used for non-hero images.
+const jpegQuality = 80
+
+// minJPEGQuality is as far as Encode lowers the quality to fit MaxBytes.
+const minJPEGQuality = 40
+
+// MaxBytes is the size the script kept each variant under, re-encoding at
+// lower quality until it fit.
+const MaxBytes = 200 * 1024
+
+// maxConcurrent bounds how many images are decoded and resized at once;
+// a large original takes a few hundred MB while it's being worked on.
+const maxConcurrent = 2
+
+// cacheControl lets browsers and CDNs keep a variant for 30 days. Sources
+// are effectively write-once (uploads get a new name), and the ETag lets
+// clients revalidate cheaply after that.
+const cacheControl = "public, max-age=2592000"
+
+// pathPattern is what an image path may look like: lowercase slug segments,
+// no extension, no dot segments.
+var pathPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*(/[a-z0-9][a-z0-9_-]*){0,3}$`)
+
+// sourceExts are the original formats looked for, in order.
+var sourceExts = []string{".jpg", ".jpeg", ".png", ".gif"}
+
+// variantExts are the formats of pre-generated variants: the script's WebP
+// and the gallery's JPEG.
+var variantExts = []string{".webp", ".jpg"}
+
+// Server serves GET /img/{preset}/{path...}.
+type Server struct {
+	Images   fs.FS  // static/img: pre-generated variants, originals under source/
+	CacheDir string // generated variants
+
+	sem      chan struct{}
+	mu       sync.Mutex
+	inflight map[string]*call
+}
+
+// call is a variant being generated, shared by every request for it.
+type call struct {
+	done chan struct{}
+	path string
+	err  error
+}
+
+// New returns a server over images, the site's static/img directory.
+func New(images fs.FS, cacheDir string) *Server {
+	return &Server{
+		Images:   images,
+		CacheDir: cacheDir,
+		sem:      make(chan struct{}, maxConcurrent),
+		inflight: make(map[string]*call),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := Presets[r.PathValue("preset")]
+	name := r.PathValue("path")
+	if !ok || len(name) > 200 || !pathPattern.MatchString(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	// A pre-generated file for this exact size.
+	if file := s.pregenerated(name, p.Name); file != "" {
+		serveFile(w, r, s.Images, file)
+		return
+	}
+
+	// Generate from the original.
+	if src, info := s.source(name); src != "" {
+		file, err := s.variant(r, src, info, name, p)
+		if err != nil {
+			if r.Context().Err() != nil {
+				return
+			}
+			log.Printf("[img] %s %s: %v", p.Name, name, err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		serveFile(w, r, os.DirFS(s.CacheDir), file)
+		return
+	}
+
+	// No original (the bundled photos only ship as variants): fall back to
+	// the nearest pre-generated width and let the browser scale it.
+	if p.Height == 0 {
+		if file := s.nearest(name, p.Width); file != "" {
+			serveFile(w, r, s.Images, file)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// source finds the original for name.
+func (s *Server) source(name string) (string, fs.FileInfo) {
+	for _, ext := range sourceExts {
+		src := path.Join("source", name+ext)
+		if info, err := fs.Stat(s.Images, src); err == nil && info.Mode().IsRegular() {
+			return src, info
+		}
+	}
+	return "", nil
+}
+
+// pregenerated returns the variant of name at preset written ahead of
+// time, or "" if there is none.
+func (s *Server) pregenerated(name, preset string) string {
+	for _, ext := range variantExts {
+		if file := name + "-" + preset + ext; exists(s.Images, file) {
+			return file
+		}
+	}
+	return ""
+}
+
+// nearest returns the narrowest pre-generated variant at least width wide,
+// or the widest one there is.
+func (s *Server) nearest(name string, width int) string {
+	var widest string
+	for _, w := range widths {
+		file := s.pregenerated(name, w)
+		if file == "" {
+			continue
+		}
+		widest = file
+		if Presets[w].Width >= width {
+			return file
+		}
+	}
+	return widest
+}
+
+// variant returns the cached file for name at p, relative to the cache
+// directory, generating it if needed. Concurrent requests for the same
+// variant share one generation.
+func (s *Server) variant(r *http.Request, src string, info fs.FileInfo, name string, p Preset) (string, error) {
+	// The key covers the source's identity, so replacing an original
+	// produces a new file (and ETag) instead of serving the old one.
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%d\x00%d", p.Name, name, info.Size(), info.ModTime().UnixNano()))
+	key := hex.EncodeToString(sum[:8])
+	stem := path.Join(p.Name, name)
+	if hits, _ := fs.Glob(os.DirFS(s.CacheDir), stem+"."+key+".*"); len(hits) > 0 {
+		return hits[0], nil
+	}
+
+	s.mu.Lock()
+	c, ok := s.inflight[key]
+	if !ok {
+		c = &call{done: make(chan struct{})}
+		s.inflight[key] = c
+		go func() {
+			c.path, c.err = s.generate(src, stem, key, p)
+			s.mu.Lock()
+			delete(s.inflight, key)
+			s.mu.Unlock()
+			close(c.done)
+		}()
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.path, c.err
+	case <-r.Context().Done():
+		return "", r.Context().Err()
+	}
+}
+
+// generate resizes src to p and writes it to the cache as stem.key.jpg, or
+// .webp when the result has transparency (see Encode). Older versions are
+// removed.
+func (s *Server) generate(src, stem, key string, p Preset) (string, error) {
+	s.sem <- struct{}{}
+	defer func() { <-s.sem }()
+	start := time.Now()
+
+	f, err := s.Images.Open(src)
+	if err != nil {
+		return "", err
+	}
+	img, _, err := imaging.Decode(f)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+	var m *image.RGBA
+	if p.Height > 0 {
+		m = imaging.Fill(img, p.Width, p.Height)
+	} else {
+		m = imaging.Resize(img, p.Width)
+	}
+
+	data, ext, err := Encode(m)
+	if err != nil {
+		return "", err
+	}
+
+	dst := filepath.Join(s.CacheDir, filepath.FromSlash(stem))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", fmt.Errorf("create cache dir: %w", err)
+	}
+	old, _ := filepath.Glob(dst + ".*")
+	file := dst + "." + key + ext
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("write cache: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("write cache: %w", err)
+	}
+	for _, o := range old {
+		os.Remove(o)
+	}
+	b := m.Bounds()
+	log.Printf("[img] generated %s (%d×%d, %dKB) in %s", filepath.Base(file), b.Dx(), b.Dy(), len(data)/1024, time.Since(start).Round(time.Millisecond))
+	return stem + "." + key + ext, nil
+}
+
+// Encode compresses a variant the way the script did: JPEG at quality 80,
+// lowered in steps until the file is at most MaxBytes. Images with
+// transparency are kept as lossless WebP when that fits, and otherwise
+// flattened onto white and sent as JPEG. It returns the file's extension
+// with the data, and an error if even the lowest quality is too large.
+func Encode(m *image.RGBA) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if !opaque(m) {
+		if err := imaging.EncodeWebP(&buf, m); err != nil {
+			return nil, "", fmt.Errorf("encode: %w", err)
+		}
+		if buf.Len() <= MaxBytes {
+			return buf.Bytes(), ".webp", nil
+		}
+		flatten(m)
+	}
+	for q := jpegQuality; ; q -= 10 {
+		buf.Reset()
+		if err := jpeg.Encode(&buf, m, &jpeg.Options{Quality: q}); err != nil {
+			return nil, "", fmt.Errorf("encode: %w", err)
+		}
+		if buf.Len() <= MaxBytes {
+			return buf.Bytes(), ".jpg", nil
+		}
+		if q-10 < minJPEGQuality {
+			return nil, "", fmt.Errorf("encode: %dKB at quality %d, over the %dKB limit", buf.Len()/1024, q, MaxBytes/1024)
+		}
+	}
+}
+
+// serveFile sends a variant with long-lived caching and an ETag from the
+// file's size and modification time.
+func serveFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	h := w.Header()
+	h.Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	h.Set("Cache-Control", cacheControl)
+	h.Set("X-Content-Type-Options", "nosniff")
+	switch path.Ext(name) {
+	case ".webp":
+		h.Set("Content-Type", "image/webp")
+	case ".jpg":
+		h.Set("Content-Type", "image/jpeg")
+	}
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	// ServeContent answers If-None-Match and Range from the headers above.
+	http.ServeContent(w, r, "", info.ModTime(), rs)
+}
+
+func exists(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// flatten composites m onto white in place, leaving it opaque.
+func flatten(m *image.RGBA) {
+	for i := 0; i < len(m.Pix); i += 4 {
+		bg := 0xff - m.Pix[i+3] // pixels are premultiplied
+		m.Pix[i] += bg
+		m.Pix[i+1] += bg
+		m.Pix[i+2] += bg
+		m.Pix[i+3] = 0xff
+	}
+}
+
+func opaque(m *image.RGBA) bool {
+	for i := 3; i < len(m.Pix); i += 4 {
+		if m.Pix[i] != 0xff {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,253 @@
+// Package schema builds schema.org structured data for the public pages and
+// renders it as a JSON-LD document. Builders produce Nodes; Validate checks a
+// Node against the schema.org types the site uses so a typo or a missing
+// required property shows up in the log instead of in Search Console.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+)
+
+// Context is the JSON-LD @context for every document.
+const Context = "https://schema.org"
+
+// Item availability values for Offer.availability.
+const (
+	InStock             = "https://schema.org/InStock"
+	LimitedAvailability = "https://schema.org/LimitedAvailability"
+	SoldOut             = "https://schema.org/SoldOut"
+)
+
+// Node is one schema.org object: its properties keyed by name, with "@type"
+// set and nested objects as Nodes or []Node.
+type Node map[string]any
+
+// Type returns the node's @type, or "" for a bare @id reference.
+func (n Node) Type() string {
+	t, _ := n["@type"].(string)
+	return t
+}
+
+// Ref returns a reference to a node defined elsewhere in the document.
+func Ref(id string) Node {
+	return Node{"@id": id}
+}
+
+// Address is a postal address.
+type Address struct {
+	Street     string
+	Locality   string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// Review is a client's quote about the business.
+type Review struct {
+	Author string
+	Body   string
+}
+
+// Business describes the outfitter for a LocalBusiness node.
+type Business struct {
+	ID          string // absolute URL with a fragment, e.g. "https://mthuntfish.com/#business"
+	Name        string
+	Description string
+	URL         string
+	Telephone   string // E.164-ish, e.g. "+1-406-459-5352"
+	Image       string
+	PriceRange  string
+	Address     Address
+	Latitude    float64
+	Longitude   float64
+	AreaServed  string // a US state name
+	Reviews     []Review
+}
+
+// LocalBusiness builds the business node.
+func LocalBusiness(b Business) Node {
+	n := Node{
+		"@type":       "LocalBusiness",
+		"@id":         b.ID,
+		"name":        b.Name,
+		"description": b.Description,
+		"url":         b.URL,
+		"telephone":   b.Telephone,
+		"address": Node{
+			"@type":           "PostalAddress",
+			"streetAddress":   b.Address.Street,
+			"addressLocality": b.Address.Locality,
+			"addressRegion":   b.Address.Region,
+			"postalCode":      b.Address.PostalCode,
+			"addressCountry":  b.Address.Country,
+		},
+		"geo": Node{
+			"@type":     "GeoCoordinates",
+			"latitude":  b.Latitude,
+			"longitude": b.Longitude,
+		},
+	}
+	if b.AreaServed != "" {
+		n["areaServed"] = Node{"@type": "State", "name": b.AreaServed}
+	}
+	if b.PriceRange != "" {
+		n["priceRange"] = b.PriceRange
+	}
+	if b.Image != "" {
+		n["image"] = b.Image
+	}
+	if len(b.Reviews) > 0 {
+		reviews := make([]Node, len(b.Reviews))
+		for i, r := range b.Reviews {
+			reviews[i] = Node{
+				"@type":      "Review",
+				"author":     Node{"@type": "Person", "name": r.Author},
+				"reviewBody": r.Body,
+			}
+		}
+		n["review"] = reviews
+	}
+	return n
+}
+
+// Quantity is a length of time in UN/CEFACT units ("HUR" for hours, "DAY"
+// for days). Min equals Max for a fixed duration.
+type Quantity struct {
+	Min, Max float64
+	Unit     string
+}
+
+func (q Quantity) node() Node {
+	n := Node{"@type": "QuantitativeValue", "unitCode": q.Unit}
+	if q.Min == q.Max {
+		n["value"] = q.Min
+	} else {
+		n["minValue"] = q.Min
+		n["maxValue"] = q.Max
+	}
+	return n
+}
+
+// Offer is one bookable way to take a trip, usually a dated season slot.
+type Offer struct {
+	Name         string    // e.g. the slot's date label
+	URL          string    // where to book
+	Price        float64   // zero when the price is on request
+	Currency     string    // ISO 4217, required with Price
+	Availability string    // InStock, LimitedAvailability or SoldOut; "" if unknown
+	Starts, Ends time.Time // zero if the slot has no concrete dates
+	Duration     *Quantity // how long the priced unit lasts; only used with Price
+}
+
+func (o Offer) node() Node {
+	n := Node{"@type": "Offer", "url": o.URL}
+	if o.Name != "" {
+		n["name"] = o.Name
+	}
+	if o.Availability != "" {
+		n["availability"] = o.Availability
+	}
+	if !o.Starts.IsZero() {
+		n["availabilityStarts"] = o.Starts.Format(time.DateOnly)
+	}
+	if !o.Ends.IsZero() {
+		n["availabilityEnds"] = o.Ends.Format(time.DateOnly)
+	}
+	if o.Price > 0 {
+		price := strconv.FormatFloat(o.Price, 'f', 2, 64)
+		n["price"] = price
+		n["priceCurrency"] = o.Currency
+		if o.Duration != nil {
+			n["priceSpecification"] = Node{
+				"@type":             "UnitPriceSpecification",
+				"price":             price,
+				"priceCurrency":     o.Currency,
+				"referenceQuantity": o.Duration.node(),
+			}
+		}
+	}
+	return n
+}
+
+// Trip describes one guided trip for a TouristTrip node.
+type Trip struct {
+	Name        string
+	Description string
+	URL         string
+	Image       string
+	TouristType string // e.g. "Fishing"
+	ProviderID  string // @id of the LocalBusiness running the trip
+	Offers      []Offer
+}
+
+// TouristTrip builds a trip node.
+func TouristTrip(t Trip) Node {
+	n := Node{
+		"@type":       "TouristTrip",
+		"name":        t.Name,
+		"description": t.Description,
+		"url":         t.URL,
+		"provider":    Ref(t.ProviderID),
+	}
+	if t.TouristType != "" {
+		n["touristType"] = t.TouristType
+	}
+	if t.Image != "" {
+		n["image"] = t.Image
+	}
+	if len(t.Offers) > 0 {
+		offers := make([]Node, len(t.Offers))
+		for i, o := range t.Offers {
+			offers[i] = o.node()
+		}
+		n["offers"] = offers
+	}
+	return n
+}
+
+// Crumb is one step in a breadcrumb trail.
+type Crumb struct {
+	Name string
+	URL  string // absolute
+}
+
+// BreadcrumbList builds a breadcrumb trail, first crumb outermost.
+func BreadcrumbList(crumbs []Crumb) Node {
+	items := make([]Node, len(crumbs))
+	for i, c := range crumbs {
+		items[i] = Node{
+			"@type":    "ListItem",
+			"position": i + 1,
+			"name":     c.Name,
+			"item":     c.URL,
+		}
+	}
+	return Node{"@type": "BreadcrumbList", "itemListElement": items}
+}
+
+// Graph renders the nodes as one JSON-LD document for a
+// <script type="application/ld+json"> tag. Nodes that fail validation are
+// left out and reported in the returned error; the rest still render.
+func Graph(nodes ...Node) (template.JS, error) {
+	var errs []error
+	graph := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		if err := Validate(n); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		graph = append(graph, n)
+	}
+	// json.Marshal escapes <, > and &, so text from content can't close the
+	// script element.
+	b, err := json.Marshal(map[string]any{"@context": Context, "@graph": graph})
+	if err != nil {
+		return "", fmt.Errorf("marshal structured data: %w", err)
+	}
+	return template.JS(b), errors.Join(errs...)
+}
@@ -0,0 +1,272 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBusiness() Business {
+	return Business{
+		ID:          "https://example.com/#business",
+		Name:        "Example Outfitters",
+		Description: "Guided trips.",
+		URL:         "https://example.com",
+		Telephone:   "+1-406-555-0100",
+		Image:       "https://example.com/hero.webp",
+		PriceRange:  "$$",
+		Address: Address{
+			Street:     "1 Main St",
+			Locality:   "Helena",
+			Region:     "MT",
+			PostalCode: "59601",
+			Country:    "US",
+		},
+		Latitude:   46.6,
+		Longitude:  -112.0,
+		AreaServed: "Montana",
+		Reviews:    []Review{{Author: "Jo", Body: "Great day on the river."}},
+	}
+}
+
+func testTrip() Trip {
+	return Trip{
+		Name:        "Jet Boat Trips",
+		Description: "Trout on the Missouri.",
+		URL:         "https://example.com/trips/fishing/#jet-boat",
+		Image:       "https://example.com/jet-boat.webp",
+		TouristType: "Fishing",
+		ProviderID:  "https://example.com/#business",
+		Offers: []Offer{
+			{
+				Name:         "Jun 1 – Jun 15",
+				URL:          "https://example.com/contact/?trip=jet-boat",
+				Price:        500,
+				Currency:     "USD",
+				Availability: LimitedAvailability,
+				Starts:       time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC),
+				Ends:         time.Date(2027, 6, 15, 0, 0, 0, 0, time.UTC),
+				Duration:     &Quantity{Min: 4, Max: 8, Unit: "HUR"},
+			},
+			{URL: "https://example.com/contact/?trip=jet-boat", Availability: SoldOut},
+		},
+	}
+}
+
+func testCrumbs() []Crumb {
+	return []Crumb{
+		{Name: "Home", URL: "https://example.com/"},
+		{Name: "Trips", URL: "https://example.com/trips/"},
+		{Name: "Fishing", URL: "https://example.com/trips/fishing/"},
+	}
+}
+
+func TestLocalBusiness(t *testing.T) {
+	n := LocalBusiness(testBusiness())
+	if err := Validate(n); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if n.Type() != "LocalBusiness" {
+		t.Errorf("@type = %q", n.Type())
+	}
+	addr := n["address"].(Node)
+	if addr.Type() != "PostalAddress" || addr["addressRegion"] != "MT" {
+		t.Errorf("address = %v", addr)
+	}
+	reviews := n["review"].([]Node)
+	if len(reviews) != 1 || reviews[0]["author"].(Node)["name"] != "Jo" {
+		t.Errorf("review = %v", reviews)
+	}
+}
+
+func TestLocalBusinessOptionalFields(t *testing.T) {
+	b := testBusiness()
+	b.AreaServed, b.PriceRange, b.Image, b.Reviews = "", "", "", nil
+	n := LocalBusiness(b)
+	if err := Validate(n); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	for _, p := range []string{"areaServed", "priceRange", "image", "review"} {
+		if _, ok := n[p]; ok {
+			t.Errorf("%s set when empty", p)
+		}
+	}
+}
+
+func TestTouristTrip(t *testing.T) {
+	n := TouristTrip(testTrip())
+	if err := Validate(n); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if n["provider"].(Node)["@id"] != "https://example.com/#business" {
+		t.Errorf("provider = %v", n["provider"])
+	}
+
+	offers := n["offers"].([]Node)
+	if len(offers) != 2 {
+		t.Fatalf("got %d offers, want 2", len(offers))
+	}
+	o := offers[0]
+	if o["price"] != "500.00" || o["priceCurrency"] != "USD" {
+		t.Errorf("price = %v %v", o["price"], o["priceCurrency"])
+	}
+	if o["availabilityStarts"] != "2027-06-01" || o["availabilityEnds"] != "2027-06-15" {
+		t.Errorf("dates = %v – %v", o["availabilityStarts"], o["availabilityEnds"])
+	}
+	q := o["priceSpecification"].(Node)["referenceQuantity"].(Node)
+	if q["minValue"] != 4.0 || q["maxValue"] != 8.0 || q["unitCode"] != "HUR" {
+		t.Errorf("referenceQuantity = %v", q)
+	}
+
+	// An offer without a price carries no price properties.
+	for _, p := range []string{"price", "priceCurrency", "priceSpecification", "availabilityStarts"} {
+		if _, ok := offers[1][p]; ok {
+			t.Errorf("unpriced offer has %s", p)
+		}
+	}
+}
+
+func TestFixedDuration(t *testing.T) {
+	n := Quantity{Min: 8, Max: 8, Unit: "HUR"}.node()
+	if n["value"] != 8.0 {
+		t.Errorf("value = %v", n["value"])
+	}
+	if _, ok := n["minValue"]; ok {
+		t.Error("minValue set for a fixed duration")
+	}
+}
+
+func TestBreadcrumbList(t *testing.T) {
+	n := BreadcrumbList(testCrumbs())
+	if err := Validate(n); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	items := n["itemListElement"].([]Node)
+	for i, item := range items {
+		if item["position"] != i+1 {
+			t.Errorf("item %d position = %v", i, item["position"])
+		}
+	}
+	if items[2]["item"] != "https://example.com/trips/fishing/" {
+		t.Errorf("last item = %v", items[2]["item"])
+	}
+}
+
+func TestValidateFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		node func() Node
+		want string
+	}{
+		{"no type", func() Node { return Node{"name": "x"} }, "no @type"},
+		{"bad reference", func() Node { return Node{"@id": "#business"} }, "not an absolute URL"},
+		{"unknown type", func() Node { return Node{"@type": "Restaurant", "name": "x"} }, `unsupported type "Restaurant"`},
+		{"missing required", func() Node {
+			n := LocalBusiness(testBusiness())
+			delete(n, "telephone")
+			return n
+		}, `missing required property "telephone"`},
+		{"blank required", func() Node {
+			b := testBusiness()
+			b.Name = "  "
+			return LocalBusiness(b)
+		}, `missing required property "name"`},
+		{"unknown property", func() Node {
+			n := LocalBusiness(testBusiness())
+			n["openingHours"] = "Mo-Su"
+			return n
+		}, `"openingHours" is not a property of LocalBusiness`},
+		{"relative url", func() Node {
+			tr := testTrip()
+			tr.URL = "/trips/fishing/"
+			return TouristTrip(tr)
+		}, "TouristTrip.url: \"/trips/fishing/\" is not an absolute URL"},
+		{"url not a string", func() Node {
+			n := TouristTrip(testTrip())
+			n["image"] = 42
+			return n
+		}, "expected a URL"},
+		{"nested failure", func() Node {
+			b := testBusiness()
+			b.Address.Locality = ""
+			return LocalBusiness(b)
+		}, `LocalBusiness.address: missing required property "addressLocality"`},
+		{"bad availability", func() Node {
+			tr := testTrip()
+			tr.Offers[0].Availability = "Available"
+			return TouristTrip(tr)
+		}, "is not an ItemAvailability"},
+		{"bad date", func() Node {
+			n := TouristTrip(testTrip())
+			n["offers"].([]Node)[0]["availabilityStarts"] = "June 1"
+			return n
+		}, "is not an ISO 8601 date"},
+		{"bad currency", func() Node {
+			tr := testTrip()
+			tr.Offers[0].Currency = "usd"
+			return TouristTrip(tr)
+		}, "is not an ISO 4217 currency code"},
+		{"price without currency", func() Node {
+			return Node{"@type": "Offer", "url": "https://example.com/contact/", "price": "500.00"}
+		}, "price without priceCurrency"},
+		{"quantity without value", func() Node {
+			return Node{"@type": "QuantitativeValue", "unitCode": "HUR", "minValue": 4.0}
+		}, "needs value or minValue and maxValue"},
+		{"breadcrumb out of order", func() Node {
+			n := BreadcrumbList(testCrumbs())
+			n["itemListElement"].([]Node)[1]["position"] = 3
+			return n
+		}, "position must be 2"},
+		{"empty breadcrumb", func() Node { return BreadcrumbList(nil) }, `missing required property "itemListElement"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.node())
+			if err == nil {
+				t.Fatalf("Validate passed, want error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraph(t *testing.T) {
+	bad := TouristTrip(Trip{Name: "Broken", ProviderID: "https://example.com/#business"})
+	js, err := Graph(LocalBusiness(testBusiness()), bad, BreadcrumbList(testCrumbs()))
+	if err == nil || !strings.Contains(err.Error(), "TouristTrip") {
+		t.Errorf("Graph error = %v, want the invalid trip reported", err)
+	}
+
+	var doc struct {
+		Context string           `json:"@context"`
+		Graph   []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal([]byte(js), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if doc.Context != Context {
+		t.Errorf("@context = %q", doc.Context)
+	}
+	var types []string
+	for _, n := range doc.Graph {
+		types = append(types, n["@type"].(string))
+	}
+	if got := strings.Join(types, ","); got != "LocalBusiness,BreadcrumbList" {
+		t.Errorf("graph types = %s, want the invalid node left out", got)
+	}
+}
+
+func TestGraphEscapesScript(t *testing.T) {
+	b := testBusiness()
+	b.Description = "</script><script>alert(1)</script>"
+	js, err := Graph(LocalBusiness(b))
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	if strings.Contains(string(js), "</script>") {
+		t.Errorf("script tag not escaped: %s", js)
+	}
+}
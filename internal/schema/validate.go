@@ -0,0 +1,195 @@
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// typeSpec lists the schema.org properties the site may set on a type and
+// the ones it must. It covers only the types the builders emit; the
+// property names come from the schema.org definitions of each type and its
+// parents.
+type typeSpec struct {
+	required []string
+	allowed  []string
+}
+
+var types = map[string]typeSpec{
+	"LocalBusiness": {
+		required: []string{"@id", "name", "address", "telephone"},
+		allowed:  []string{"description", "url", "geo", "areaServed", "priceRange", "image", "review", "sameAs"},
+	},
+	"PostalAddress": {
+		required: []string{"addressLocality", "addressRegion", "addressCountry"},
+		allowed:  []string{"streetAddress", "postalCode"},
+	},
+	"GeoCoordinates": {
+		required: []string{"latitude", "longitude"},
+	},
+	"State": {
+		required: []string{"name"},
+	},
+	"Review": {
+		required: []string{"author", "reviewBody"},
+		allowed:  []string{"itemReviewed", "reviewRating", "datePublished"},
+	},
+	"Person": {
+		required: []string{"name"},
+	},
+	"TouristTrip": {
+		required: []string{"name", "description", "provider"},
+		allowed:  []string{"url", "image", "touristType", "offers", "itinerary"},
+	},
+	"Offer": {
+		required: []string{"url"},
+		allowed:  []string{"name", "price", "priceCurrency", "availability", "availabilityStarts", "availabilityEnds", "priceSpecification"},
+	},
+	"UnitPriceSpecification": {
+		required: []string{"price", "priceCurrency"},
+		allowed:  []string{"referenceQuantity"},
+	},
+	"QuantitativeValue": {
+		required: []string{"unitCode"},
+		allowed:  []string{"value", "minValue", "maxValue"},
+	},
+	"BreadcrumbList": {
+		required: []string{"itemListElement"},
+	},
+	"ListItem": {
+		required: []string{"position", "name", "item"},
+	},
+}
+
+// urlProps must hold absolute http(s) URLs.
+var urlProps = []string{"@id", "url", "item", "image"}
+
+var availabilities = []string{InStock, LimitedAvailability, SoldOut}
+
+// Validate checks n and every node nested in it against the schema.org
+// types above: the type must be known, required properties present and
+// non-empty, no unknown properties, and URLs, dates and enumerations well
+// formed.
+func Validate(n Node) error {
+	return validate(n, "")
+}
+
+func validate(n Node, path string) error {
+	typ := n.Type()
+	if typ == "" {
+		if len(n) != 1 || n["@id"] == nil {
+			return fmt.Errorf("%s: node has no @type", where(path))
+		}
+		return checkURL(n["@id"], where(path)+".@id")
+	}
+	if path == "" {
+		path = typ
+	}
+	spec, ok := types[typ]
+	if !ok {
+		return fmt.Errorf("%s: unsupported type %q", path, typ)
+	}
+
+	for _, p := range spec.required {
+		if empty(n[p]) {
+			return fmt.Errorf("%s: missing required property %q", path, p)
+		}
+	}
+	keys := make([]string, 0, len(n))
+	for k := range n {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "@type" {
+			continue
+		}
+		if !slices.Contains(spec.required, k) && !slices.Contains(spec.allowed, k) {
+			return fmt.Errorf("%s: %q is not a property of %s", path, k, typ)
+		}
+		if err := checkValue(k, n[k], path+"."+k); err != nil {
+			return err
+		}
+	}
+
+	if typ == "BreadcrumbList" {
+		items, _ := n["itemListElement"].([]Node)
+		for i, item := range items {
+			if item["position"] != i+1 {
+				return fmt.Errorf("%s.itemListElement[%d]: position must be %d", path, i, i+1)
+			}
+		}
+	}
+	if typ == "Offer" && n["price"] != nil && empty(n["priceCurrency"]) {
+		return fmt.Errorf("%s: price without priceCurrency", path)
+	}
+	if typ == "QuantitativeValue" && n["value"] == nil && (n["minValue"] == nil || n["maxValue"] == nil) {
+		return fmt.Errorf("%s: needs value or minValue and maxValue", path)
+	}
+	return nil
+}
+
+func checkValue(prop string, v any, path string) error {
+	switch v := v.(type) {
+	case Node:
+		return validate(v, path)
+	case []Node:
+		for i, child := range v {
+			if err := validate(child, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch {
+	case slices.Contains(urlProps, prop):
+		return checkURL(v, path)
+	case prop == "availability":
+		if !slices.Contains(availabilities, fmt.Sprint(v)) {
+			return fmt.Errorf("%s: %q is not an ItemAvailability", path, v)
+		}
+	case prop == "availabilityStarts" || prop == "availabilityEnds" || prop == "datePublished":
+		if _, err := time.Parse(time.DateOnly, fmt.Sprint(v)); err != nil {
+			return fmt.Errorf("%s: %q is not an ISO 8601 date", path, v)
+		}
+	case prop == "priceCurrency":
+		if s := fmt.Sprint(v); len(s) != 3 || strings.ToUpper(s) != s {
+			return fmt.Errorf("%s: %q is not an ISO 4217 currency code", path, v)
+		}
+	}
+	return nil
+}
+
+func checkURL(v any, path string) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("%s: expected a URL, got %T", path, v)
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s: %q is not an absolute URL", path, s)
+	}
+	return nil
+}
+
+func empty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []Node:
+		return len(v) == 0
+	}
+	return false
+}
+
+func where(path string) string {
+	if path == "" {
+		return "node"
+	}
+	return path
+}
@@ -0,0 +1,82 @@
+// Package sitefs layers directories of site files so a deployment can
+// replace individual templates or static files without rebuilding.
+package sitefs
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+)
+
+// Overlay is a stack of file systems, topmost first. A file is read from
+// the first layer that has it; a directory lists the union of all layers.
+type Overlay []fs.FS
+
+// Open opens name from the topmost layer that has it.
+func (o Overlay) Open(name string) (fs.File, error) {
+	var firstErr error
+	for _, layer := range o {
+		f, err := layer.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Stat describes name as the topmost layer that has it sees it.
+func (o Overlay) Stat(name string) (fs.FileInfo, error) {
+	for _, layer := range o {
+		info, err := fs.Stat(layer, name)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir lists the entries of name across every layer, sorted by name.
+// Where layers disagree, the upper layer's entry wins.
+func (o Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := make(map[string]bool)
+	var out []fs.DirEntry
+	found := false
+	for _, layer := range o {
+		entries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, e := range entries {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				out = append(out, e)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	slices.SortFunc(out, func(a, b fs.DirEntry) int {
+		switch {
+		case a.Name() < b.Name():
+			return -1
+		case a.Name() > b.Name():
+			return 1
+		}
+		return 0
+	})
+	return out, nil
+}
@@ -0,0 +1,261 @@
+// Package sitemap builds sitemap.xml from the site's page routes and content
+// sources, switching to a sitemap index when there are too many URLs for one
+// file.
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxURLs is the sitemap protocol's limit on URLs per file.
+const MaxURLs = 50000
+
+// URL is one page in the sitemap. Loc is a site-relative path such as
+// "/trips/fishing/"; the sitemap prefixes it with the configured site URL.
+type URL struct {
+	Loc        string
+	LastMod    time.Time // zero if unknown
+	ChangeFreq string    // optional: daily, weekly, monthly...
+	Priority   float64   // optional: 0.0–1.0, zero omits the tag
+}
+
+// Source lists URLs for one section of the site, e.g. every fishing report.
+type Source func() ([]URL, error)
+
+type section struct {
+	name string
+	src  Source
+}
+
+// Sitemap collects sections of URLs and serves them as XML.
+type Sitemap struct {
+	base    string
+	perFile int
+
+	mu       sync.Mutex
+	sections []section
+}
+
+// New creates a sitemap whose URLs are rooted at baseURL, e.g.
+// "https://mthuntfish.com".
+func New(baseURL string) *Sitemap {
+	return &Sitemap{base: strings.TrimRight(baseURL, "/"), perFile: MaxURLs}
+}
+
+// BaseURL returns the site URL the sitemap was configured with.
+func (s *Sitemap) BaseURL() string {
+	return s.base
+}
+
+// Add registers a section. Sections appear in the order they were added.
+func (s *Sitemap) Add(name string, src Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sections = append(s.sections, section{name: name, src: src})
+}
+
+// Page registers a single fixed page whose last modification comes from
+// lastMod (nil if unknown).
+func (s *Sitemap) Page(path string, priority float64, changeFreq string, lastMod func() time.Time) {
+	s.Add(path, func() ([]URL, error) {
+		u := URL{Loc: path, Priority: priority, ChangeFreq: changeFreq}
+		if lastMod != nil {
+			u.LastMod = lastMod()
+		}
+		return []URL{u}, nil
+	})
+}
+
+// FileModTime returns a lastmod func reporting the newest modification time
+// among the given files in fsys. Missing files, and files embedded in the
+// binary (which have no time), are ignored.
+func FileModTime(fsys fs.FS, paths ...string) func() time.Time {
+	return func() time.Time {
+		var newest time.Time
+		for _, p := range paths {
+			if info, err := fs.Stat(fsys, p); err == nil && info.ModTime().After(newest) {
+				newest = info.ModTime()
+			}
+		}
+		return newest
+	}
+}
+
+// Newest combines lastmod funcs, returning the latest of their times.
+func Newest(fns ...func() time.Time) func() time.Time {
+	return func() time.Time {
+		var newest time.Time
+		for _, fn := range fns {
+			if t := fn(); t.After(newest) {
+				newest = t
+			}
+		}
+		return newest
+	}
+}
+
+// chunk is one sitemap file's worth of URLs from a single section.
+type chunk struct {
+	name string
+	urls []URL
+}
+
+// collect gathers every section. Small sitemaps come back as one chunk; when
+// the total exceeds the per-file limit each section is split into chunks of
+// at most perFile URLs.
+func (s *Sitemap) collect() ([]chunk, error) {
+	s.mu.Lock()
+	sections := append([]section(nil), s.sections...)
+	s.mu.Unlock()
+
+	var all []chunk
+	total := 0
+	for _, sec := range sections {
+		urls, err := sec.src()
+		if err != nil {
+			return nil, fmt.Errorf("sitemap section %s: %w", sec.name, err)
+		}
+		all = append(all, chunk{name: sec.name, urls: urls})
+		total += len(urls)
+	}
+	if total <= s.perFile {
+		var urls []URL
+		for _, c := range all {
+			urls = append(urls, c.urls...)
+		}
+		return []chunk{{urls: urls}}, nil
+	}
+
+	var out []chunk
+	for _, c := range all {
+		for i := 0; i < len(c.urls); i += s.perFile {
+			end := min(i+s.perFile, len(c.urls))
+			out = append(out, chunk{name: c.name, urls: c.urls[i:end]})
+		}
+	}
+	return out, nil
+}
+
+// ServeHTTP serves /sitemap.xml: a urlset for small sites, otherwise an
+// index pointing at /sitemaps/N.xml files.
+func (s *Sitemap) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	chunks, err := s.collect()
+	if err != nil {
+		log.Printf("[sitemap] %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	if len(chunks) == 1 {
+		err = s.writeURLSet(w, chunks[0].urls)
+	} else {
+		err = s.writeIndex(w, chunks)
+	}
+	if err != nil {
+		log.Printf("[sitemap] write: %v", err)
+	}
+}
+
+// ServePart serves one file of a sitemap index, /sitemaps/{part} where part
+// is "N.xml".
+func (s *Sitemap) ServePart(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(strings.TrimSuffix(r.PathValue("part"), ".xml"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	chunks, err := s.collect()
+	if err != nil {
+		log.Printf("[sitemap] %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if len(chunks) == 1 || n < 1 || n > len(chunks) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	if err := s.writeURLSet(w, chunks[n-1].urls); err != nil {
+		log.Printf("[sitemap] write: %v", err)
+	}
+}
+
+type xmlURLSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []xmlURL `xml:"url"`
+}
+
+type xmlURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type xmlIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []xmlSitemap `xml:"sitemap"`
+}
+
+type xmlSitemap struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (s *Sitemap) writeURLSet(w io.Writer, urls []URL) error {
+	set := xmlURLSet{URLs: make([]xmlURL, 0, len(urls))}
+	for _, u := range urls {
+		x := xmlURL{Loc: s.base + u.Loc, LastMod: formatLastMod(u.LastMod), ChangeFreq: u.ChangeFreq}
+		if u.Priority > 0 {
+			x.Priority = strconv.FormatFloat(u.Priority, 'f', 1, 64)
+		}
+		set.URLs = append(set.URLs, x)
+	}
+	return writeXML(w, set)
+}
+
+func (s *Sitemap) writeIndex(w io.Writer, chunks []chunk) error {
+	var idx xmlIndex
+	for i, c := range chunks {
+		var newest time.Time
+		for _, u := range c.urls {
+			if u.LastMod.After(newest) {
+				newest = u.LastMod
+			}
+		}
+		idx.Sitemaps = append(idx.Sitemaps, xmlSitemap{
+			Loc:     fmt.Sprintf("%s/sitemaps/%d.xml", s.base, i+1),
+			LastMod: formatLastMod(newest),
+		})
+	}
+	return writeXML(w, idx)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,47 @@
+package spam
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter counts hits per key over a sliding window. Counts live in memory,
+// so a restart forgets them; that's fine for a contact form.
+type limiter struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	hits  map[string][]time.Time
+	swept time.Time
+}
+
+func newLimiter(window time.Duration) *limiter {
+	return &limiter{window: window, hits: map[string][]time.Time{}}
+}
+
+// hit records a hit for key at now and returns how many it has had within
+// the window, this one included.
+func (l *limiter) hit(key string, now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	if now.Sub(l.swept) > l.window {
+		// Forget keys that have gone quiet so the map doesn't grow forever.
+		for k, times := range l.hits {
+			if !times[len(times)-1].After(cutoff) {
+				delete(l.hits, k)
+			}
+		}
+		l.swept = now
+	}
+
+	times := l.hits[key]
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	times = append(times[i:], now)
+	l.hits[key] = times
+	return len(times)
+}
@@ -0,0 +1,180 @@
+// Package spam scores contact form submissions. Each layer adds points and
+// a reason: rate limits per IP and per email, a signed token that shows how
+// long the form was open, link and keyword heuristics, the honeypot, and an
+// optional CAPTCHA verifier. A submission at or over Threshold is suspected
+// spam; the caller keeps it in quarantine rather than dropping it, so a
+// false positive can be recovered.
+package spam
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Threshold is the score at which a submission is treated as spam.
+const Threshold = 5
+
+// Rate limits. Going over a soft limit counts toward the score; going over
+// a hard limit means the submission should be refused outright, so a flood
+// can't fill the quarantine.
+const (
+	ipWindow    = 10 * time.Minute
+	ipSoft      = 5
+	ipHard      = 20
+	emailWindow = time.Hour
+	emailSoft   = 3
+)
+
+// Submission is what the scorer looks at.
+type Submission struct {
+	IP       string
+	Email    string
+	Name     string
+	Message  string
+	Token    string // the form token issued with the form
+	Honeypot string // the hidden field only bots fill in
+	Captcha  string // the CAPTCHA provider's response, if one is configured
+}
+
+// Verdict is the result of scoring a submission.
+type Verdict struct {
+	Score   int
+	Reasons []string
+	Limited bool // over the hard rate limit; refuse the submission
+}
+
+// Spam reports whether the submission should be quarantined.
+func (v Verdict) Spam() bool {
+	return v.Score >= Threshold
+}
+
+func (v *Verdict) add(points int, format string, args ...any) {
+	v.Score += points
+	v.Reasons = append(v.Reasons, fmt.Sprintf(format, args...))
+}
+
+// Scorer scores submissions. It is safe for concurrent use.
+type Scorer struct {
+	tokens   *tokens
+	verifier Verifier // nil when no CAPTCHA is configured
+	ips      *limiter
+	emails   *limiter
+	now      func() time.Time
+}
+
+// New returns a scorer signing form tokens with key. verifier may be nil.
+func New(key []byte, verifier Verifier) *Scorer {
+	return &Scorer{
+		tokens:   &tokens{key: key},
+		verifier: verifier,
+		ips:      newLimiter(ipWindow),
+		emails:   newLimiter(emailWindow),
+		now:      time.Now,
+	}
+}
+
+// Score runs every layer over a submission and records it against the
+// rate limits.
+func (s *Scorer) Score(ctx context.Context, sub Submission) Verdict {
+	now := s.now()
+	var v Verdict
+
+	if sub.Honeypot != "" {
+		v.add(Threshold, "filled in the hidden website field")
+	}
+
+	if sub.IP != "" {
+		n := s.ips.hit(sub.IP, now)
+		if n > ipHard {
+			v.Limited = true
+		}
+		if n > ipSoft {
+			v.add(Threshold, "%d submissions from %s in %s", n, sub.IP, ipWindow)
+		}
+	}
+	if sub.Email != "" {
+		if n := s.emails.hit(strings.ToLower(sub.Email), now); n > emailSoft {
+			v.add(Threshold, "%d submissions from this email in %s", n, emailWindow)
+		}
+	}
+
+	switch age, err := s.tokens.check(sub.Token, now); {
+	case err == errNoToken:
+		v.add(Threshold, "no form token (the form wasn't loaded from the site)")
+	case err != nil:
+		v.add(Threshold, "form token %v", err)
+	case age < MinFillTime:
+		v.add(4, "sent %.1fs after the form loaded", age.Seconds())
+	case age > MaxTokenAge:
+		v.add(2, "form was loaded %s ago", age.Round(time.Hour))
+	}
+
+	if links := countLinks(sub.Message); links >= 3 {
+		v.add(3, "%d links in the message", links)
+	}
+	if linkMarkup.MatchString(sub.Message) {
+		v.add(3, "link markup in the message")
+	}
+	if countLinks(sub.Name) > 0 {
+		v.add(Threshold, "link in the name")
+	}
+	for _, kw := range keywords(sub.Name + "\n" + sub.Message) {
+		v.add(2, "keyword %q", kw)
+	}
+
+	if s.verifier != nil {
+		ok, err := s.verifier.Verify(ctx, sub.Captcha, sub.IP)
+		switch {
+		case err != nil:
+			// A provider outage shouldn't quarantine every real client.
+			log.Printf("[spam] CAPTCHA verifier: %v", err)
+		case !ok:
+			v.add(Threshold, "failed the CAPTCHA")
+		}
+	}
+	return v
+}
+
+var (
+	linkPattern = regexp.MustCompile(`(?i)\bhttps?://|\bwww\.`)
+	linkMarkup  = regexp.MustCompile(`(?i)\[url[=\]]|<a\s+href`)
+)
+
+func countLinks(s string) int {
+	return len(linkPattern.FindAllStringIndex(s, -1))
+}
+
+// spamWords are phrases real trip inquiries don't use. Each distinct one
+// found adds to the score.
+var spamWords = []string{
+	"seo", "backlink", "backlinks", "guest post", "first page of google",
+	"rank your website", "web design services", "marketing services",
+	"casino", "crypto", "bitcoin", "forex", "viagra", "cialis",
+	"payday loan", "loan offer", "adult dating", "unsubscribe",
+}
+
+var spamWordPattern = func() *regexp.Regexp {
+	quoted := make([]string, len(spamWords))
+	for i, w := range spamWords {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}()
+
+// keywords returns the distinct spam phrases in s, lowercased.
+func keywords(s string) []string {
+	var found []string
+	seen := map[string]bool{}
+	for _, m := range spamWordPattern.FindAllString(s, -1) {
+		m = strings.ToLower(m)
+		if !seen[m] {
+			seen[m] = true
+			found = append(found, m)
+		}
+	}
+	return found
+}
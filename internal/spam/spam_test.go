@@ -0,0 +1,272 @@
+package spam
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clock is a settable time source for a Scorer.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestScorer(v Verifier) (*Scorer, *clock) {
+	c := &clock{t: time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)}
+	s := New([]byte("0123456789abcdef0123456789abcdef"), v)
+	s.now = c.now
+	return s, c
+}
+
+// clean returns a submission that scores nothing: a token issued a minute
+// ago and an ordinary trip question.
+func clean(s *Scorer, c *clock) Submission {
+	token := s.tokens.issue(c.now().Add(-time.Minute))
+	return Submission{
+		IP:      "203.0.113.7",
+		Email:   "jo@example.com",
+		Name:    "Jo Angler",
+		Message: "Hi, we're looking at a float trip on the Missouri for two in late June. What dates are open?",
+		Token:   token,
+	}
+}
+
+func TestCleanSubmission(t *testing.T) {
+	s, c := newTestScorer(nil)
+	v := s.Score(context.Background(), clean(s, c))
+	if v.Score != 0 || v.Spam() || v.Limited {
+		t.Errorf("verdict = %+v, want a clean pass", v)
+	}
+}
+
+func TestHeuristics(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(*Submission)
+		score  int
+		reason string
+	}{
+		{"honeypot", func(sub *Submission) { sub.Honeypot = "http://spam.example" }, Threshold, "hidden website field"},
+		{"three links", func(sub *Submission) {
+			sub.Message = "see http://a.example and https://b.example or www.c.example"
+		}, 3, "3 links"},
+		{"two links", func(sub *Submission) { sub.Message = "http://a.example http://b.example" }, 0, ""},
+		{"bbcode link", func(sub *Submission) { sub.Message = "[url=http://a.example]deal[/url]" }, 3, "link markup"},
+		{"html link", func(sub *Submission) { sub.Message = `<a  href="x">deal</a>` }, 3, "link markup"},
+		{"link in name", func(sub *Submission) { sub.Name = "www.cheap.example" }, Threshold, "link in the name"},
+		{"keyword", func(sub *Submission) { sub.Message = "We offer SEO for outfitters." }, 2, `keyword "seo"`},
+		{"keyword counted once", func(sub *Submission) { sub.Message = "Crypto! crypto. CRYPTO" }, 2, `keyword "crypto"`},
+		{"keywords add up", func(sub *Submission) {
+			sub.Message = "Guest post and backlinks to get you on the first page of Google."
+		}, 6, "first page of google"},
+		{"keyword in name", func(sub *Submission) { sub.Name = "Casino Deals" }, 2, `keyword "casino"`},
+		{"not a keyword inside a word", func(sub *Submission) { sub.Message = "Seoul to Helena via Forexample" }, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := newTestScorer(nil)
+			sub := clean(s, c)
+			tt.edit(&sub)
+			v := s.Score(context.Background(), sub)
+			if v.Score != tt.score {
+				t.Errorf("score = %d, want %d (%v)", v.Score, tt.score, v.Reasons)
+			}
+			if got := strings.Join(v.Reasons, "; "); !strings.Contains(got, tt.reason) {
+				t.Errorf("reasons = %q, want one containing %q", got, tt.reason)
+			}
+			if v.Spam() != (tt.score >= Threshold) {
+				t.Errorf("Spam() = %v at score %d", v.Spam(), v.Score)
+			}
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		wait   time.Duration
+		token  func(s *Scorer) string
+		score  int
+		reason string
+	}{
+		{"filled in normally", time.Minute, (*Scorer).Token, 0, ""},
+		{"right at the minimum", MinFillTime, (*Scorer).Token, 0, ""},
+		{"too fast", MinFillTime - time.Second, (*Scorer).Token, 4, "sent 2.0s after the form loaded"},
+		{"stale", MaxTokenAge + time.Hour, (*Scorer).Token, 2, "form was loaded 25h0m0s ago"},
+		{"missing", time.Minute, func(*Scorer) string { return "" }, Threshold, "no form token"},
+		{"forged", time.Minute, func(s *Scorer) string {
+			ts, _, _ := strings.Cut(s.Token(), ".")
+			return ts + ".AAAAAAAAAAAAAAAAAAAAAA"
+		}, Threshold, "doesn't match its signature"},
+		{"backdated", time.Minute, func(s *Scorer) string {
+			ts, sig, _ := strings.Cut(s.Token(), ".")
+			unix, _ := strconv.ParseInt(ts, 10, 64)
+			return strconv.FormatInt(unix-3600, 10) + "." + sig
+		}, Threshold, "doesn't match its signature"},
+		{"other key", time.Minute, func(s *Scorer) string {
+			other, _ := newTestScorer(nil)
+			other.tokens.key = []byte("a different key, same timestamp")
+			return other.Token()
+		}, Threshold, "doesn't match its signature"},
+		{"from the future", 0, func(s *Scorer) string {
+			return s.tokens.issue(s.now().Add(time.Hour))
+		}, Threshold, "is from the future"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := newTestScorer(nil)
+			sub := clean(s, c)
+			sub.Token = tt.token(s)
+			c.advance(tt.wait)
+			v := s.Score(context.Background(), sub)
+			if v.Score != tt.score {
+				t.Errorf("score = %d, want %d (%v)", v.Score, tt.score, v.Reasons)
+			}
+			if got := strings.Join(v.Reasons, "; "); !strings.Contains(got, tt.reason) {
+				t.Errorf("reasons = %q, want one containing %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+func TestIPLimit(t *testing.T) {
+	s, c := newTestScorer(nil)
+	for i := 1; i <= ipHard+1; i++ {
+		sub := clean(s, c)
+		sub.Email = "" // count the IP alone
+		v := s.Score(context.Background(), sub)
+		if spam := i > ipSoft; v.Spam() != spam {
+			t.Errorf("submission %d: Spam() = %v, want %v (%v)", i, v.Spam(), spam, v.Reasons)
+		}
+		if limited := i > ipHard; v.Limited != limited {
+			t.Errorf("submission %d: Limited = %v, want %v", i, v.Limited, limited)
+		}
+	}
+
+	// Another address is counted separately.
+	sub := clean(s, c)
+	sub.IP, sub.Email = "198.51.100.2", ""
+	if v := s.Score(context.Background(), sub); v.Score != 0 {
+		t.Errorf("other IP scored %d (%v)", v.Score, v.Reasons)
+	}
+
+	// Once the window has passed the address starts over.
+	c.advance(ipWindow)
+	sub = clean(s, c)
+	sub.Email = ""
+	if v := s.Score(context.Background(), sub); v.Score != 0 || v.Limited {
+		t.Errorf("after the window: %+v", v)
+	}
+}
+
+func TestEmailLimit(t *testing.T) {
+	s, c := newTestScorer(nil)
+	for i := 1; i <= emailSoft+1; i++ {
+		sub := clean(s, c)
+		sub.IP = "" // count the email alone
+		if i == emailSoft+1 {
+			sub.Email = "JO@Example.com" // case doesn't make a new sender
+		}
+		v := s.Score(context.Background(), sub)
+		if spam := i > emailSoft; v.Spam() != spam {
+			t.Errorf("submission %d: Spam() = %v, want %v (%v)", i, v.Spam(), spam, v.Reasons)
+		}
+		if v.Limited {
+			t.Errorf("submission %d: the email limit has no hard cap", i)
+		}
+	}
+
+	c.advance(emailWindow)
+	sub := clean(s, c)
+	sub.IP = ""
+	if v := s.Score(context.Background(), sub); v.Score != 0 {
+		t.Errorf("after the window: scored %d (%v)", v.Score, v.Reasons)
+	}
+}
+
+func TestLimiterSlides(t *testing.T) {
+	l := newLimiter(10 * time.Minute)
+	start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, want := range []int{1, 2, 3} {
+		if got := l.hit("a", start.Add(time.Duration(i)*4*time.Minute)); got != want {
+			t.Errorf("hit %d = %d, want %d", i, got, want)
+		}
+	}
+	// At 12:12 the 12:00 hit has left the window; 12:04 and 12:08 remain.
+	if got := l.hit("a", start.Add(12*time.Minute)); got != 3 {
+		t.Errorf("hit at 12:12 = %d, want 3", got)
+	}
+	// A quiet key is swept once a window has passed.
+	l.hit("b", start.Add(30*time.Minute))
+	if _, ok := l.hits["a"]; ok {
+		t.Error("quiet key not swept")
+	}
+}
+
+// stubVerifier answers every check the same way.
+type stubVerifier struct {
+	ok  bool
+	err error
+}
+
+func (v stubVerifier) Verify(ctx context.Context, response, remoteIP string) (bool, error) {
+	return v.ok, v.err
+}
+
+func TestCaptcha(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier Verifier
+		response string
+		score    int
+	}{
+		{"fake passes its response", Fake{}, FakeResponse, 0},
+		{"fake fails anything else", Fake{}, "bot-guess", Threshold},
+		{"fake fails an empty response", Fake{}, "", Threshold},
+		{"provider outage doesn't count", stubVerifier{err: errors.New("timeout")}, "x", 0},
+		{"provider says no", stubVerifier{ok: false}, "x", Threshold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := newTestScorer(tt.verifier)
+			sub := clean(s, c)
+			sub.Captcha = tt.response
+			if v := s.Score(context.Background(), sub); v.Score != tt.score {
+				t.Errorf("score = %d, want %d (%v)", v.Score, tt.score, v.Reasons)
+			}
+		})
+	}
+}
+
+func TestFuncMapWidget(t *testing.T) {
+	s, _ := newTestScorer(Fake{})
+	widget := s.FuncMap()["captchaWidget"].(func() template.HTML)()
+	if !strings.Contains(string(widget), `value="`+FakeResponse+`"`) {
+		t.Errorf("fake widget = %s", widget)
+	}
+	s, _ = newTestScorer(nil)
+	if widget := s.FuncMap()["captchaWidget"].(func() template.HTML)(); widget != "" {
+		t.Errorf("widget without a verifier = %s", widget)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "form.key")
+	key, err := LoadKey(path)
+	if err != nil {
+		t.Fatalf("first LoadKey: %v", err)
+	}
+	again, err := LoadKey(path)
+	if err != nil {
+		t.Fatalf("second LoadKey: %v", err)
+	}
+	if string(key) != string(again) {
+		t.Error("key changed between loads")
+	}
+}
@@ -0,0 +1,118 @@
+package spam
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How long a form must be open before it is sent, and how old its token
+// may be, for the submission not to count against it.
+const (
+	MinFillTime = 3 * time.Second
+	MaxTokenAge = 24 * time.Hour
+)
+
+var (
+	errNoToken  = errors.New("missing")
+	errBadToken = errors.New("doesn't match its signature")
+	errFuture   = errors.New("is from the future")
+)
+
+// tokens issues and checks form tokens: the time the form was rendered,
+// signed so a bot can't backdate it.
+type tokens struct {
+	key []byte
+}
+
+func (t *tokens) sign(ts string) string {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte("form:" + ts))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (t *tokens) issue(now time.Time) string {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	return ts + "." + t.sign(ts)
+}
+
+// check returns how long ago the token was issued.
+func (t *tokens) check(token string, now time.Time) (time.Duration, error) {
+	if token == "" {
+		return 0, errNoToken
+	}
+	ts, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(t.sign(ts))) {
+		return 0, errBadToken
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return 0, errBadToken
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age < -time.Minute {
+		return 0, errFuture
+	}
+	return age, nil
+}
+
+// Token returns a fresh form token to embed in the contact form.
+func (s *Scorer) Token() string {
+	return s.tokens.issue(s.now())
+}
+
+// FuncMap provides the form token and the CAPTCHA widget, if the verifier
+// has one, to templates:
+//
+//	<input type="hidden" name="form_token" value="{{formToken}}">
+//	{{captchaWidget}}
+func (s *Scorer) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"formToken": s.Token,
+		"captchaWidget": func() template.HTML {
+			if w, ok := s.verifier.(Widget); ok {
+				return w.Widget()
+			}
+			return ""
+		},
+	}
+}
+
+// LoadKey reads the token signing key from path, creating it with a random
+// key on first use. Keeping it on disk means forms loaded before a restart
+// still verify after it.
+func LoadKey(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+		if err != nil || len(key) < 16 {
+			return nil, fmt.Errorf("spam: %s doesn't hold a valid key", path)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("spam: read key: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("spam: generate key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("spam: create key directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("spam: write key: %w", err)
+	}
+	return key, nil
+}
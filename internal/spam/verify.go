@@ -0,0 +1,40 @@
+package spam
+
+import (
+	"context"
+	"html/template"
+)
+
+// Verifier checks a CAPTCHA response with its provider. A provider plugs in
+// by implementing it; the form posts the widget's response as
+// captcha_response.
+type Verifier interface {
+	// Verify reports whether response is a valid solve from remoteIP. An
+	// error means the provider couldn't be asked, not that the check failed.
+	Verify(ctx context.Context, response, remoteIP string) (bool, error)
+}
+
+// Widget is implemented by verifiers that render their own form field, such
+// as the provider's script tag and placeholder. The contact form includes
+// it through {{captchaWidget}}.
+type Widget interface {
+	Widget() template.HTML
+}
+
+// FakeResponse is the only response Fake accepts.
+const FakeResponse = "fake-captcha-pass"
+
+// Fake is a local Verifier for tests and development. It passes exactly
+// FakeResponse and fails anything else, without calling out anywhere.
+type Fake struct{}
+
+// Verify implements Verifier.
+func (Fake) Verify(ctx context.Context, response, remoteIP string) (bool, error) {
+	return response == FakeResponse, nil
+}
+
+// Widget implements Widget with a hidden field that always passes, so the
+// form works end to end with the fake in place.
+func (Fake) Widget() template.HTML {
+	return template.HTML(`<input type="hidden" name="captcha_response" value="` + FakeResponse + `">`)
+}
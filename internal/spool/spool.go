@@ -0,0 +1,224 @@
+// Package spool keeps inquiries the database couldn't take in an
+// append-only JSONL file next to it, one inquiry per line, until a replay
+// gets them into SQLite. A full disk or a locked database then delays a
+// lead instead of losing it.
+package spool
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/firefly/packstring/internal/db"
+	"github.com/firefly/packstring/internal/metrics"
+)
+
+// Entry is one spooled inquiry.
+type Entry struct {
+	SpooledAt time.Time  `json:"spooled_at"`
+	Error     string     `json:"error"` // why the database refused it
+	Inquiry   db.Inquiry `json:"inquiry"`
+}
+
+// Spool is a spool file.
+type Spool struct {
+	path string
+	mu   sync.Mutex
+
+	// pending is how many lines the file holds, kept up to date by Append
+	// and Replay so only the first caller reads the whole file.
+	pending int
+	counted bool
+}
+
+// New returns the spool at path. The file is created on first use.
+func New(path string) *Spool {
+	return &Spool{path: path}
+}
+
+// Path returns the spool file's location.
+func (s *Spool) Path() string { return s.path }
+
+// Append adds an inquiry to the spool and syncs it to disk before
+// returning, so a nil error means the inquiry is safe. The inquiry's
+// CreatedAt is set to now if empty, so the replayed row keeps the time the
+// client sent it.
+func (s *Spool) Append(inq *db.Inquiry, cause error) error {
+	if inq.CreatedAt.IsZero() {
+		inq.CreatedAt = time.Now().UTC()
+	}
+	e := Entry{SpooledAt: time.Now().UTC(), Inquiry: *inq}
+	if cause != nil {
+		e.Error = cause.Error()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("spool inquiry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("spool inquiry: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("spool inquiry: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("spool inquiry: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("spool inquiry: %w", err)
+	}
+	if s.counted {
+		s.setPending(s.pending + 1)
+	} else {
+		s.count() // the inquiry is safe; a failed count is retried later
+	}
+	return nil
+}
+
+// Pending returns how many inquiries are waiting in the spool, counting
+// lines that can't be parsed.
+func (s *Spool) Pending() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counted {
+		return s.pending, nil
+	}
+	return s.count()
+}
+
+// count reads the spool to learn how many lines it holds. The caller holds
+// s.mu.
+func (s *Spool) count() (int, error) {
+	entries, corrupt, err := s.read()
+	if err != nil {
+		return 0, err
+	}
+	n := len(entries) + len(corrupt)
+	s.setPending(n)
+	return n, nil
+}
+
+// setPending records the spool's depth and updates the metric. The caller
+// holds s.mu.
+func (s *Spool) setPending(n int) {
+	s.pending, s.counted = n, true
+	metrics.InquirySpool.Set(float64(n))
+}
+
+// read parses the spool. Lines that aren't valid entries (a write cut
+// short, a hand edit gone wrong) are returned as they are, so they're kept
+// for a person to look at rather than dropped.
+func (s *Spool) read() (entries []Entry, corrupt [][]byte, err error) {
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read spool: %w", err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			log.Printf("[spool] %s line %d unreadable: %v", s.path, n, err)
+			corrupt = append(corrupt, bytes.Clone(line))
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, corrupt, sc.Err()
+}
+
+// Replay moves spooled inquiries into the store. Inquiries that still
+// can't be saved stay in the spool for the next run. Replay re-reads the
+// file, so it also corrects the pending count after a hand edit. Replay is safe to
+// repeat: an inquiry already in the database isn't inserted twice, so a
+// crash between saving and rewriting the spool does no harm.
+func (s *Spool) Replay(store *db.Store) (replayed, remaining int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, corrupt, err := s.read()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(entries) == 0 {
+		s.setPending(len(corrupt))
+		return 0, len(corrupt), nil
+	}
+
+	var keep []Entry
+	var lastErr error
+	for _, e := range entries {
+		id, err := store.RestoreInquiry(&e.Inquiry)
+		if err != nil {
+			lastErr = err
+			keep = append(keep, e)
+			continue
+		}
+		replayed++
+		if id != 0 {
+			log.Printf("[spool] replayed inquiry #%d from %s <%s>, spooled %s", id, e.Inquiry.Name, e.Inquiry.Email, e.SpooledAt.Format(time.RFC3339))
+		}
+	}
+	if err := s.rewrite(keep, corrupt); err != nil {
+		s.setPending(len(entries) + len(corrupt))
+		return replayed, len(entries) + len(corrupt), err
+	}
+	remaining = len(keep) + len(corrupt)
+	s.setPending(remaining)
+	if lastErr != nil {
+		return replayed, remaining, fmt.Errorf("%d inquiries still spooled: %w", len(keep), lastErr)
+	}
+	return replayed, remaining, nil
+}
+
+// rewrite replaces the spool with entries and corrupt lines, removing it
+// when nothing is left.
+func (s *Spool) rewrite(entries []Entry, corrupt [][]byte) error {
+	if len(entries) == 0 && len(corrupt) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("clear spool: %w", err)
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("rewrite spool: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	for _, line := range corrupt {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("rewrite spool: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("rewrite spool: %w", err)
+	}
+	return nil
+}
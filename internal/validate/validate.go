@@ -0,0 +1,104 @@
+// Package validate checks and normalizes the free-text fields people type
+// into the site's public forms. Each check returns the cleaned-up value and
+// whether it passed; the caller decides what to tell the user.
+package validate
+
+import (
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Errors maps field names to the message shown beside that field.
+type Errors map[string]string
+
+// Add records msg for field unless the field already has an error, so the
+// first problem found is the one reported.
+func (e Errors) Add(field, msg string) {
+	if _, ok := e[field]; !ok {
+		e[field] = msg
+	}
+}
+
+// Any reports whether there are errors.
+func (e Errors) Any() bool { return len(e) > 0 }
+
+// MaxLen reports whether s is at most n characters.
+func MaxLen(s string, n int) bool {
+	return utf8.RuneCountInString(s) <= n
+}
+
+// Email parses s as an RFC 5322 address and returns the bare address with
+// its domain lowercased. Display names ("Ann <ann@example.com>") are
+// rejected, as are domains without a dot, which no one can email.
+func Email(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return "", false
+	}
+	at := strings.LastIndexByte(addr.Address, '@')
+	local, domain := addr.Address[:at], strings.ToLower(addr.Address[at+1:])
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", false
+	}
+	return local + "@" + domain, true
+}
+
+// Phone normalizes a phone number to E.164, e.g. "(406) 459-5352" to
+// "+14064595352". Numbers without a leading + are read as North American,
+// which is where nearly every caller is; others need their country code.
+func Phone(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	international := strings.HasPrefix(s, "+")
+	var digits strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	d := digits.String()
+	switch {
+	case international:
+		// E.164 allows up to 15 digits; no country's numbers are shorter
+		// than 8 with the country code.
+		if len(d) < 8 || len(d) > 15 || d[0] == '0' {
+			return "", false
+		}
+		return "+" + d, true
+	case len(d) == 11 && d[0] == '1':
+		d = d[1:]
+	case len(d) != 10:
+		return "", false
+	}
+	// North American area codes and exchanges don't start with 0 or 1.
+	if d[0] < '2' || d[3] < '2' {
+		return "", false
+	}
+	return "+1" + d, true
+}
+
+// Int parses s as a whole number between min and max inclusive.
+func Int(s string, min, max int) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < min || n > max {
+		return 0, false
+	}
+	return n, true
+}
+
+// OneOf reports whether s is one of allowed.
+func OneOf(s string, allowed ...string) bool {
+	for _, a := range allowed {
+		if s == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package views
+
+import (
+	"bufio"
+	"html/template"
+	"path"
+	"regexp"
+	"strconv"
+)
+
+// errorPos finds the file and line in html/template and text/template
+// errors, e.g. `template: home.html:12:5: executing "content" at <.Foo>`.
+var errorPos = regexp.MustCompile(`template: ([\w.-]+\.html):(\d+)`)
+
+// contextLines is how much source the overlay shows around the error.
+const contextLines = 5
+
+type overlayData struct {
+	Page   string
+	Error  string
+	File   string
+	Line   int
+	Source []sourceLine
+}
+
+type sourceLine struct {
+	N    int
+	Text string
+	Bad  bool
+}
+
+func errorLocation(err error) (file string, line int, ok bool) {
+	m := errorPos.FindStringSubmatch(err.Error())
+	if m == nil {
+		return "", 0, false
+	}
+	line, _ = strconv.Atoi(m[2])
+	return m[1], line, true
+}
+
+// excerpt returns the lines around line in the template file named base,
+// looking in each template directory. Templates are named by base name, so
+// the first match is the one that was parsed.
+func (l *Loader) excerpt(base string, line int) []sourceLine {
+	for _, dir := range []string{path.Dir(layoutsGlob), path.Dir(partialsGlob), pagesDir} {
+		f, err := l.fsys.Open(path.Join(dir, base))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		var out []sourceLine
+		sc := bufio.NewScanner(f)
+		for n := 1; sc.Scan(); n++ {
+			if n >= line-contextLines && n <= line+contextLines {
+				out = append(out, sourceLine{N: n, Text: sc.Text(), Bad: n == line})
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// overlayTemplate is self-contained so it works when the site's own
+// templates don't.
+var overlayTemplate = template.Must(template.New("overlay").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>Template error — {{.Page}}</title>
+<style>
+  body { margin: 0; background: #1A1410; color: #F5EFE6; font: 15px/1.5 system-ui, sans-serif; }
+  main { max-width: 960px; margin: 0 auto; padding: 48px 24px; }
+  .label { font-size: 11px; letter-spacing: .3em; text-transform: uppercase; color: #B8652A; margin: 0 0 8px; }
+  h1 { font-size: 22px; margin: 0 0 24px; font-weight: 600; }
+  pre { margin: 0; white-space: pre-wrap; word-break: break-word; font: 13px/1.6 ui-monospace, Menlo, monospace; }
+  .error { background: rgba(184,101,42,.15); border: 1px solid rgba(184,101,42,.5); border-radius: 4px; padding: 16px; color: #F0C9A8; }
+  .source { margin-top: 24px; background: #241C16; border-radius: 4px; padding: 12px 0; overflow-x: auto; }
+  .source div { display: flex; padding: 0 16px; }
+  .source .n { flex: 0 0 48px; color: #7A6A5C; text-align: right; padding-right: 16px; user-select: none; }
+  .source .bad { background: rgba(184,101,42,.3); }
+  .hint { margin-top: 24px; color: #A89888; font-size: 13px; }
+</style>
+</head>
+<body>
+<main>
+  <p class="label">Template error</p>
+  <h1>{{.Page}}{{if .File}} &middot; {{.File}}:{{.Line}}{{end}}</h1>
+  <pre class="error">{{.Error}}</pre>
+  {{if .Source}}
+  <pre class="source">{{range .Source}}<div{{if .Bad}} class="bad"{{end}}><span class="n">{{.N}}</span><span>{{.Text}}</span></div>{{end}}</pre>
+  {{end}}
+  <p class="hint">Fix the template and reload. This page only appears with PACKSTRING_DEV=1.</p>
+</main>
+</body>
+</html>
+`))
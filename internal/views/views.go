@@ -0,0 +1,212 @@
+// Package views loads the site's page templates. Each page is the base
+// layout plus every partial plus one file from templates/pages, parsed into
+// its own set so their "content" blocks don't collide. In dev mode a page
+// is re-parsed when any of its files change, and template errors render as
+// an overlay describing the problem instead of a bare 500.
+package views
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+// Directories every page is built from, relative to the site root.
+const (
+	layoutsGlob  = "templates/layouts/*.html"
+	partialsGlob = "templates/partials/*.html"
+	pagesDir     = "templates/pages"
+)
+
+// Pages maps the names handlers render by to their templates.
+type Pages map[string]*Page
+
+// Loader parses pages from a site tree.
+type Loader struct {
+	fsys fs.FS
+	dev  bool
+
+	mu    sync.Mutex
+	pages []*Page
+}
+
+// NewLoader returns a loader reading from fsys, which holds templates/. In
+// dev mode pages reload on change and errors are shown in the browser.
+func NewLoader(fsys fs.FS, dev bool) *Loader {
+	return &Loader{fsys: fsys, dev: dev}
+}
+
+// Page is one parsed page template.
+type Page struct {
+	loader *Loader
+	file   string
+	funcs  []template.FuncMap
+
+	mu    sync.Mutex
+	tmpl  *template.Template
+	err   error
+	stamp stamp
+}
+
+// stamp identifies a version of a page's files: if none was added, removed
+// or modified, the newest time and the count are unchanged.
+type stamp struct {
+	newest time.Time
+	n      int
+}
+
+// Page parses templates/pages/file with the layouts and partials, using
+// funcs in order. Outside dev mode a parse error is returned; in dev mode
+// it is kept and shown when the page is rendered, so a typo doesn't stop
+// the server.
+func (l *Loader) Page(file string, funcs ...template.FuncMap) (*Page, error) {
+	p := &Page{loader: l, file: file, funcs: funcs}
+	p.stamp = p.files()
+	p.tmpl, p.err = p.parse()
+	if p.err != nil {
+		if !l.dev {
+			return nil, p.err
+		}
+		log.Printf("[views] %v", p.err)
+	}
+	l.mu.Lock()
+	l.pages = append(l.pages, p)
+	l.mu.Unlock()
+	return p, nil
+}
+
+// Check reports whether every page loaded so far parses, for readiness
+// checks. Only dev mode can have a broken page, since elsewhere a parse
+// error stops the server at startup.
+func (l *Loader) Check() error {
+	l.mu.Lock()
+	pages := append([]*Page(nil), l.pages...)
+	l.mu.Unlock()
+	if len(pages) == 0 {
+		return errors.New("views: no pages loaded")
+	}
+	var errs []error
+	for _, p := range pages {
+		if _, err := p.current(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MustPage is like Page but panics on error, like template.Must.
+func (l *Loader) MustPage(file string, funcs ...template.FuncMap) *Page {
+	p, err := l.Page(file, funcs...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Page) parse() (*template.Template, error) {
+	t := template.New("")
+	for _, f := range p.funcs {
+		t.Funcs(f)
+	}
+	for _, pattern := range []string{layoutsGlob, partialsGlob, path.Join(pagesDir, p.file)} {
+		if _, err := t.ParseFS(p.loader.fsys, pattern); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// files stats everything the page is built from.
+func (p *Page) files() stamp {
+	var s stamp
+	names := []string{path.Join(pagesDir, p.file)}
+	for _, g := range []string{layoutsGlob, partialsGlob} {
+		matches, _ := fs.Glob(p.loader.fsys, g)
+		names = append(names, matches...)
+	}
+	for _, name := range names {
+		info, err := fs.Stat(p.loader.fsys, name)
+		if err != nil {
+			continue
+		}
+		s.n++
+		if info.ModTime().After(s.newest) {
+			s.newest = info.ModTime()
+		}
+	}
+	return s
+}
+
+// current returns the page's template, re-parsing it first in dev mode if
+// its files changed.
+func (p *Page) current() (*template.Template, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loader.dev {
+		if s := p.files(); s != p.stamp {
+			p.stamp = s
+			p.tmpl, p.err = p.parse()
+			if p.err != nil {
+				log.Printf("[views] %v", p.err)
+			} else {
+				log.Printf("[views] reloaded %s", p.file)
+			}
+		}
+	}
+	return p.tmpl, p.err
+}
+
+// ExecuteTemplate renders the named template of the page to w, matching
+// html/template's method so handlers render pages as they always have.
+//
+// In dev mode the output is buffered; a parse or execution error is logged
+// and replaced by the error overlay, and nil is returned so the handler
+// doesn't append its own error text.
+func (p *Page) ExecuteTemplate(w io.Writer, name string, data any) error {
+	t, err := p.current()
+	if !p.loader.dev {
+		if err != nil {
+			return err
+		}
+		return t.ExecuteTemplate(w, name, data)
+	}
+
+	var buf bytes.Buffer
+	if err == nil {
+		if err = t.ExecuteTemplate(&buf, name, data); err != nil {
+			log.Printf("[views] %s: %v", p.file, err)
+		}
+	}
+	if err != nil {
+		p.loader.overlay(w, p.file, err)
+		return nil
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// overlay writes the dev error page. Responses get a 500 so the failure is
+// obvious in the network panel and to htmx.
+func (l *Loader) overlay(w io.Writer, page string, err error) {
+	d := overlayData{Page: page, Error: err.Error()}
+	if file, line, ok := errorLocation(err); ok {
+		d.File, d.Line = file, line
+		d.Source = l.excerpt(file, line)
+	}
+	if rw, ok := w.(http.ResponseWriter); ok {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+	if err := overlayTemplate.Execute(w, d); err != nil {
+		fmt.Fprintf(w, "template error: %s", d.Error)
+	}
+}
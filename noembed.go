@@ -0,0 +1,9 @@
+//go:build !embed
+
+package packstring
+
+import "io/fs"
+
+// Embedded is nil without the embed build tag; the server reads templates/,
+// static/ and reports/ from the working directory instead.
+var Embedded fs.FS
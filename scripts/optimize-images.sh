@@ -11,6 +11,9 @@ set -euo pipefail
 # Gallery images also produce a 400x300 crop thumbnail (-thumb.webp)
 # Hero images use quality 85; all others use quality 80.
 # If a file exceeds 200KB, it is re-encoded at progressively lower quality.
+#
+# Photos uploaded through /admin/gallery/ are processed by the server and
+# don't need this script; their originals are kept in the same source dir.
 
 SCRIPT_DIR="$(cd "$(dirname "$0")" && pwd)"
 PROJECT_ROOT="$(dirname "$SCRIPT_DIR")"
@@ -0,0 +1,4 @@
+// Package packstring carries the site's templates, static files and
+// fishing reports for builds made with -tags embed, so the binary runs from
+// any directory.
+package packstring